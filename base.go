@@ -0,0 +1,181 @@
+package chanassert
+
+import "fmt"
+
+// CombinerBase provides the bookkeeping shared by all of the built-in combiners, and
+// is intended to be embedded by custom [Combiner] implementations. It provides:
+//   - IsSatisfied and IsSaturated, which report the state most recently recorded
+//     using SetSatisfied/SetSaturated (or UpdateSatisfiedCount/UpdateSaturatedCount),
+//   - detection of the combiner being used by more than one layer at a time (see [Combiner]).
+//     Once the expecter using the combiner has finished, the combiner is Reset (which should
+//     call ResetBase) when it is next added to a layer, so that it can be reused.
+//
+// Alongside the helpers such as [NewCombinerStatusTrace] and [NewMatcherCountsTrace], a custom
+// combiner is able to produce traces which are consistent with those of the built-in combiners.
+//
+// CombinerBase must not be copied once the combiner has been added to a layer.
+type CombinerBase struct {
+	ownership
+
+	// satisfied indicates whether this matcher can be considered 'done' by
+	// the layer which holds it. The matcher *may* be able to accept more messages (assuming
+	// it's not also saturated), however it doesn't *need* to do so.
+	satisfied bool
+
+	// saturated indicates whether the combiner is able to match against
+	// more messages. Depending on the combiner, this value is set under different circumstances.
+	// Once saturated, any call to TryMatch will return false.
+	saturated bool
+
+	// name is the name given to the combiner by the user, if any.
+	name string
+}
+
+// IsSatisfied returns whether the combiner was most recently recorded as satisfied.
+func (base *CombinerBase) IsSatisfied() bool {
+	return base.satisfied
+}
+
+// IsSaturated returns whether the combiner was most recently recorded as saturated.
+func (base *CombinerBase) IsSaturated() bool {
+	return base.saturated
+}
+
+// SetSatisfied records whether the combiner is satisfied, returning
+// a trace which reports this alongside the reason provided.
+func (base *CombinerBase) SetSatisfied(satisfied bool, reason string) TraceMessage {
+	base.satisfied = satisfied
+	return newSatisfiedTrace(satisfied, reason)
+}
+
+// SetSaturated records whether the combiner is saturated, returning
+// a trace which reports this alongside the reason provided.
+func (base *CombinerBase) SetSaturated(saturated bool, reason string) TraceMessage {
+	base.saturated = saturated
+	return newSaturatedTrace(saturated, reason)
+}
+
+// UpdateSatisfiedCount records the combiner as satisfied if the count provided is AT LEAST MIN and
+// NO MORE THAN MAX. The subject describes what was counted, and is used as-is in the trace (e.g.
+// "SUM of all matched messages" for [ExactlyNOf]).
+func (base *CombinerBase) UpdateSatisfiedCount(subject string, count int, min int, max int) TraceMessage {
+	switch {
+	case count > max:
+		return base.SetSatisfied(false, fmt.Sprintf("%s (%d) has exceeded maximum (%d) messages", subject, count, max))
+	case count < min:
+		return base.SetSatisfied(false, fmt.Sprintf("%s (%d) must meet %d messages", subject, count, min))
+	}
+
+	return base.SetSatisfied(true, fmt.Sprintf("%s (%d) has met minimum (%d) messages", subject, count, min))
+}
+
+// UpdateSaturatedCount records the combiner as saturated if the count provided is AT LEAST MAX.
+// The subject describes what was counted, as for UpdateSatisfiedCount.
+func (base *CombinerBase) UpdateSaturatedCount(subject string, count int, max int) TraceMessage {
+	if count >= max {
+		return base.SetSaturated(true, fmt.Sprintf("%s (%d) has met maximum (%d) messages", subject, count, max))
+	}
+
+	return base.SetSaturated(false, fmt.Sprintf("%s (%d) must be at least %d", subject, count, max))
+}
+
+// Name returns the name given to the combiner using SetName, or an empty string
+// if the combiner has not been named. Named combiners are referred to by their
+// name, rather than their index, in traces and errors.
+func (base *CombinerBase) Name() string {
+	return base.name
+}
+
+// SetName names the combiner. Custom combiners will typically expose this
+// as a chainable Named method, consistent with the built-in combiners.
+func (base *CombinerBase) SetName(name string) {
+	base.name = name
+}
+
+// ResetBase clears the state held by the CombinerBase (other than its name). Custom
+// combiners should call this from their Reset method.
+func (base *CombinerBase) ResetBase() {
+	base.satisfied = false
+	base.saturated = false
+}
+
+// NewSatisfiedTrace returns the trace used by the built-in combiners to report
+// whether they are satisfied, with the reason as debug output.
+func NewSatisfiedTrace(isSatisfied bool, reason string) TraceMessage {
+	return newSatisfiedTrace(isSatisfied, reason)
+}
+
+// NewSaturatedTrace returns the trace used by the built-in combiners to report
+// whether they are saturated, with the reason as debug output.
+func NewSaturatedTrace(isSaturated bool, reason string) TraceMessage {
+	return newSaturatedTrace(isSaturated, reason)
+}
+
+// NewCombinerStatusTrace returns the "Combiner status" trace which the built-in combiners nest
+// within the trace returned from TryMatch. The mode describes the combiner (e.g. "SUM mode
+// with minimum of 1 and maximum of 2"), and any details provided follow the satisfied and saturated traces.
+func NewCombinerStatusTrace(mode string, satisfied TraceMessage, saturated TraceMessage, details ...TraceMessage) TraceMessage {
+	status := []TraceMessage{newInfoTrace(mode), satisfied, saturated}
+	return newInfoTrace("Combiner status", append(status, details...)...)
+}
+
+// NewMatcherCountsTrace returns the trace (at [LevelTrace]) which reports how many
+// messages each of a combiners matchers has matched.
+func NewMatcherCountsTrace(counts []int) TraceMessage {
+	details := make([]TraceMessage, 0, len(counts))
+	for k, count := range counts {
+		details = append(details, newMatcherCountTrace(k, count))
+	}
+
+	return NewTrace(LevelTrace, "Matcher counts", details...)
+}
+
+// NewMatcherAcceptTrace returns the trace used by the built-in
+// combiners when the matcher at index idx accepts a message.
+func NewMatcherAcceptTrace(idx int) TraceMessage {
+	return newInfoTrace(fmt.Sprintf("Matcher #%d ACCEPT", idx))
+}
+
+// NewMatcherRejectTrace returns the trace used by the built-in combiners when
+// the matcher at index idx does not accept a message, for the reason provided.
+func NewMatcherRejectTrace(idx int, reason string) TraceMessage {
+	return newErrorTrace(fmt.Sprintf("Matcher #%d REJECT: %s", idx, reason))
+}
+
+// NewDescribedMatcherAcceptTrace is like [NewMatcherAcceptTrace], but describes the
+// matcher if it implements [Describer], as the built-in combiners do.
+func NewDescribedMatcherAcceptTrace[T any](idx int, matcher Matcher[T]) TraceMessage {
+	return newInfoTrace(fmt.Sprintf("%s ACCEPT", matcherLabel(idx, matcher, defaultValueFormatter)))
+}
+
+// NewDescribedMatcherRejectTrace is like [NewMatcherRejectTrace], but describes the
+// matcher if it implements [Describer], as the built-in combiners do.
+func NewDescribedMatcherRejectTrace[T any](idx int, matcher Matcher[T], reason string) TraceMessage {
+	return newErrorTrace(fmt.Sprintf("%s REJECT: %s", matcherLabel(idx, matcher, defaultValueFormatter), reason))
+}
+
+// NewCombinerMatchedTrace returns the trace used by the built-in combiners when a message
+// is accepted by the matcher at index idx. The attempts are the traces of each matcher tried.
+func NewCombinerMatchedTrace(idx int, attempts ...TraceMessage) TraceMessage {
+	return newInfoTrace(fmt.Sprintf("Combiner matched on matcher #%d", idx), attempts...)
+}
+
+// NewCombinerFailedTrace returns the trace used by the built-in combiners when a message
+// is not accepted by any matcher. The attempts are the traces of each matcher tried.
+func NewCombinerFailedTrace(attempts ...TraceMessage) TraceMessage {
+	return newErrorTrace("Combiner failed match message", attempts...)
+}
+
+// NewCombinerSaturatedTrace returns the trace used by the built-in combiners
+// when a message is offered to a combiner which is already saturated.
+func NewCombinerSaturatedTrace() TraceMessage {
+	return newInfoTrace("Combiner is fully saturated, accepting no further messages")
+}
+
+func newMatcherCountTrace(idx int, count int) TraceMessage {
+	if count > 0 {
+		return newInfoTrace(fmt.Sprintf("Matcher #%d => %d message(s)", idx, count))
+	}
+
+	return newInfoTrace(fmt.Sprintf("Matcher #%d => 0 messages", idx))
+}
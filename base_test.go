@@ -0,0 +1,217 @@
+package chanassert_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hbomb79/go-chanassert"
+)
+
+// sumCombiner is a custom combiner built using the exported toolkit, which
+// behaves identically to the built-in 'sum' combiners (e.g. BetweenNOf).
+type sumCombiner struct {
+	chanassert.CombinerBase
+
+	matchers []chanassert.Matcher[string]
+	min, max int
+	counts   []int
+}
+
+func newSumCombiner(min, max int, matchers ...chanassert.Matcher[string]) *sumCombiner {
+	return &sumCombiner{matchers: matchers, min: min, max: max, counts: make([]int, len(matchers))}
+}
+
+func (c *sumCombiner) TryMatch(message string) (bool, chanassert.TraceMessage) {
+	ok, trace := c.tryMatch(message)
+
+	sum := 0
+	for _, count := range c.counts {
+		sum += count
+	}
+
+	trace.Nested = append(trace.Nested, chanassert.NewCombinerStatusTrace(
+		fmt.Sprintf("SUM mode with minimum of %d and maximum of %d", c.min, c.max),
+		c.UpdateSatisfiedCount("SUM of all matched messages", sum, c.min, c.max),
+		c.UpdateSaturatedCount("SUM of all matched messages", sum, c.max),
+		chanassert.NewMatcherCountsTrace(c.counts),
+	))
+
+	return ok, trace
+}
+
+func (c *sumCombiner) tryMatch(message string) (bool, chanassert.TraceMessage) {
+	if c.IsSaturated() {
+		return false, chanassert.NewCombinerSaturatedTrace()
+	}
+
+	attempts := make([]chanassert.TraceMessage, 0)
+	for i, m := range c.matchers {
+		if !m.DoesMatch(message) {
+			attempts = append(attempts, chanassert.NewDescribedMatcherRejectTrace(i, m, "no match"))
+			continue
+		}
+
+		c.counts[i]++
+		attempts = append(attempts, chanassert.NewDescribedMatcherAcceptTrace(i, m))
+		return true, chanassert.NewCombinerMatchedTrace(i, attempts...)
+	}
+
+	return false, chanassert.NewCombinerFailedTrace(attempts...)
+}
+
+func (c *sumCombiner) Reset() {
+	c.counts = make([]int, len(c.matchers))
+	c.ResetBase()
+}
+
+func Test_CombinerBase(t *testing.T) {
+	makeCombiner := func() chanassert.Combiner[string] {
+		return newSumCombiner(1, 2, chanassert.MatchEqual("hello"), chanassert.MatchEqual("world"))
+	}
+
+	tests := []combinerTest[string]{
+		{
+			summary:   "Minimum messages",
+			messages:  []string{"foo", "world"},
+			expected:  []bool{false, true},
+			satisfied: []bool{false, true},
+		},
+		{
+			summary:   "Saturated after maximum messages",
+			messages:  []string{"hello", "world", "hello"},
+			expected:  []bool{true, true, false},
+			satisfied: []bool{true, true, true},
+		},
+	}
+
+	runCombinerTests(t, makeCombiner, tests)
+}
+
+func Test_CombinerBase_Trace(t *testing.T) {
+	custom := newSumCombiner(1, 2, chanassert.MatchEqual("hello"), chanassert.MatchEqual("world"))
+	builtin := chanassert.BetweenNOf(1, 2, chanassert.MatchEqual("hello"), chanassert.MatchEqual("world"))
+
+	for _, msg := range []string{"world", "foo", "hello", "hello"} {
+		_, customTrace := custom.TryMatch(msg)
+		_, builtinTrace := builtin.TryMatch(msg)
+
+		customOutput, builtinOutput := &strings.Builder{}, &strings.Builder{}
+		customTrace.PrintTrace(customOutput, true, 0)
+		builtinTrace.PrintTrace(builtinOutput, true, 0)
+
+		if customOutput.String() != builtinOutput.String() {
+			t.Errorf("expected custom combiner trace for message %q to match built-in trace:\n%s\nbut got:\n%s", msg, builtinOutput, customOutput)
+		}
+
+		if custom.IsSaturated() != builtin.IsSaturated() {
+			t.Errorf("expected custom combiner saturation (%v) to match built-in saturation (%v) after message %q", custom.IsSaturated(), builtin.IsSaturated(), msg)
+		}
+	}
+}
+
+func Test_CombinerBase_UpdateSatisfiedCount(t *testing.T) {
+	tests := []struct {
+		count     int
+		satisfied bool
+		expected  string
+	}{
+		{count: 0, satisfied: false, expected: "Pending acks (0) must meet 1 messages"},
+		{count: 2, satisfied: true, expected: "Pending acks (2) has met minimum (1) messages"},
+		{count: 3, satisfied: false, expected: "Pending acks (3) has exceeded maximum (2) messages"},
+	}
+
+	for _, test := range tests {
+		base := &chanassert.CombinerBase{}
+		output := &strings.Builder{}
+		base.UpdateSatisfiedCount("Pending acks", test.count, 1, 2).PrintTrace(output, true, 0)
+
+		if base.IsSatisfied() != test.satisfied {
+			t.Errorf("expected satisfied to be %v for count %d, got %v", test.satisfied, test.count, base.IsSatisfied())
+		}
+
+		if !strings.Contains(output.String(), test.expected) {
+			t.Errorf("expected trace for count %d to contain %q, got:\n%s", test.count, test.expected, output)
+		}
+	}
+}
+
+func Test_MatcherTraces_Undescribed(t *testing.T) {
+	for _, tc := range []struct {
+		trace    chanassert.TraceMessage
+		expected string
+	}{
+		{chanassert.NewMatcherAcceptTrace(1), "Matcher #1 ACCEPT"},
+		{chanassert.NewMatcherRejectTrace(1, "no match"), "Matcher #1 REJECT: no match"},
+	} {
+		output := &strings.Builder{}
+		tc.trace.PrintTrace(output, false, 0)
+		if !strings.Contains(output.String(), tc.expected+"\n") {
+			t.Errorf("expected trace to contain line %q, but it did not:\n%s", tc.expected, output)
+		}
+	}
+}
+
+func Test_CombinerBase_Reuse(t *testing.T) {
+	shared := newSumCombiner(1, 1, chanassert.MatchEqual("a"))
+	assertPanics := func(summary string) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected custom combiner %s to panic", summary)
+			}
+		}()
+
+		chanassert.NewChannelExpecter(make(chan string)).Expect(newSumCombiner(1, 1, chanassert.MatchEqual("b")), shared)
+	}
+
+	ch := make(chan string, 1)
+	exp := chanassert.NewChannelExpecter(ch).Expect(shared)
+	exp.Listen()
+	assertPanics("reused by another expecter, while the expecter using it is listening")
+
+	ch <- "a"
+	exp.AwaitSatisfied(time.Second)
+	if !shared.IsSatisfied() {
+		t.Fatalf("expected custom combiner to be satisfied")
+	}
+
+	// The combiner is reset when it is reused, as the expecter using it has finished
+	chanassert.NewChannelExpecter(make(chan string)).Expect(shared)
+	if shared.IsSatisfied() {
+		t.Errorf("expected custom combiner to be reset when reused")
+	}
+}
+
+// minimalCombiner implements only the methods required by the Combiner interface, as
+// combiners written before IsSaturated and Reset were introduced do.
+type minimalCombiner struct {
+	matched bool
+}
+
+func (c *minimalCombiner) TryMatch(message string) (bool, chanassert.TraceMessage) {
+	if c.matched || message != "hello" {
+		return false, chanassert.NewInfoTrace("Minimal combiner rejected message")
+	}
+
+	c.matched = true
+	return true, chanassert.NewInfoTrace("Minimal combiner accepted message")
+}
+
+func (c *minimalCombiner) IsSatisfied() bool { return c.matched }
+
+func Test_MinimalCombiner(t *testing.T) {
+	ch := make(chan string, 1)
+	exp := chanassert.NewChannelExpecter(ch).Expect(&minimalCombiner{}, chanassert.MayOccur(1, chanassert.MatchEqual("world")))
+	exp.Listen()
+
+	ch <- "hello"
+	if errs := exp.AwaitSatisfied(time.Second); len(errs) != 0 {
+		t.Errorf("expected expecter using a minimal combiner to be satisfied, got errors: %v", errs)
+	}
+
+	report := exp.Export().Layers[0].Report
+	if report == nil || len(report.Combiners) != 2 || report.Combiners[0].Saturated || !report.Combiners[0].Satisfied {
+		t.Errorf("expected minimal combiner to be reported as satisfied and NOT saturated, got %+v", report)
+	}
+}
@@ -0,0 +1,251 @@
+package chanassert
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Captures holds the named values which have been bound by [Capture]
+// matchers as messages were accepted by the expecter.
+type Captures map[string]any
+
+// String returns the captures as a list of name=value pairs, sorted
+// by name so that the output is stable. The values are not redacted; the
+// trace and exports of an expecter instead use the values as formatted by
+// its formatter (see [Expecter.FormatMessages]).
+func (captures Captures) String() string {
+	names := make([]string, 0, len(captures))
+	for name := range captures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	str := strings.Builder{}
+	for idx, name := range names {
+		if idx > 0 {
+			str.WriteString(", ")
+		}
+		fmt.Fprintf(&str, "%s=%v", name, captures[name])
+	}
+
+	return str.String()
+}
+
+// Capture returns a matcher which matches messages using the matcher provided. When
+// a message matched by this matcher is ACCEPTED by the combiner which holds it, the value
+// returned by extract is bound to the name provided. Binding a name which is already bound
+// will replace the previous value.
+//
+// Values bound by a capture can be referred to by matchers in later combiners
+// and layers using [MatchCaptured] or [MatchCapturedPredicate].
+func Capture[T any](name string, extract func(T) any, matcher Matcher[T]) *captureMatcher[T] {
+	return &captureMatcher[T]{name: name, extract: extract, matcher: matcher}
+}
+
+// MatchCaptured returns a matcher which accepts messages where the value returned
+// by extract is deeply equal to the value bound to the name provided (see [Capture]). If
+// no value has been bound to the name yet, the message will not match.
+func MatchCaptured[T any](name string, extract func(T) any) *capturedMatcher[T] {
+	matcher := MatchCapturedPredicate(name, func(message T, value any) bool {
+		return reflect.DeepEqual(extract(message), value)
+	})
+	matcher.description = fmt.Sprintf("equals captured %q", name)
+	matcher.opaque = false
+
+	return matcher
+}
+
+// MatchCapturedPredicate returns a matcher which accepts messages which return true when passed
+// to the predicate provided, alongside the value bound to the name provided (see [Capture]). If
+// no value has been bound to the name yet, the predicate is not called and the message will not match.
+func MatchCapturedPredicate[T any](name string, predicate func(message T, value any) bool) *capturedMatcher[T] {
+	return &capturedMatcher[T]{name: name, predicate: predicate, description: fmt.Sprintf("matches predicate with captured %q", name), opaque: true}
+}
+
+type captureMatcher[T any] struct {
+	name    string
+	extract func(T) any
+	matcher Matcher[T]
+}
+
+// DoesMatch matches the message against the wrapped matcher. Note
+// that no value is bound when calling DoesMatch directly, as values are only
+// bound once a message has been accepted by a combiner.
+func (capture *captureMatcher[T]) DoesMatch(message T) bool {
+	return capture.matcher.DoesMatch(message)
+}
+
+func (capture *captureMatcher[T]) doesMatchCaptures(message T, scope *captureScope) bool {
+	return matchWithCaptures(capture.matcher, message, scope)
+}
+
+func (capture *captureMatcher[T]) Describe() string {
+	return capture.describeWith(defaultValueFormatter)
+}
+
+func (capture *captureMatcher[T]) describeWith(values *valueFormatter) string {
+	return fmt.Sprintf("%s, capturing %q", describeWith(capture.matcher, values), capture.name)
+}
+
+func (capture *captureMatcher[T]) explainMatch(message T, scope *captureScope) (bool, string) {
+	return explainMatch(capture.matcher, message, scope)
+}
+
+func (capture *captureMatcher[T]) capture(message T, scope *captureScope) Captures {
+	captured := captureMatched(capture.matcher, message, scope)
+	if captured == nil {
+		captured = make(Captures, 1)
+	}
+
+	value := capture.extract(message)
+	formatted := scope.formatter().formatCaptured(reflect.ValueOf(message), value)
+	scope.bind(capture.name, value, formatted)
+	captured[capture.name] = formatted
+
+	return captured
+}
+
+type capturedMatcher[T any] struct {
+	name        string
+	predicate   func(message T, value any) bool
+	description string
+
+	// opaque is true if the predicate was provided by the test (see isOpaque).
+	opaque bool
+}
+
+func (captured *capturedMatcher[T]) Describe() string {
+	return captured.description
+}
+
+// DoesMatch always returns false, as the captured values
+// are only available to matchers used inside of an expecter.
+func (captured *capturedMatcher[T]) DoesMatch(T) bool {
+	return false
+}
+
+func (captured *capturedMatcher[T]) doesMatchCaptures(message T, scope *captureScope) bool {
+	value, ok := scope.lookup(captured.name)
+	if !ok {
+		return false
+	}
+
+	return captured.predicate(message, value)
+}
+
+// captureAwareMatcher is implemented by matchers which need access to the
+// values captured by the expecter in order to match a message.
+type captureAwareMatcher[T any] interface {
+	doesMatchCaptures(message T, scope *captureScope) bool
+}
+
+// capturingMatcher is implemented by matchers which bind values once a message they
+// matched has been accepted. The values bound are returned, as formatted for the trace.
+type capturingMatcher[T any] interface {
+	capture(message T, scope *captureScope) Captures
+}
+
+// captureCombiner is implemented by combiners which are able to make use of
+// the values captured by the expecter when matching a message.
+type captureCombiner[T any] interface {
+	tryMatchCaptures(message T, scope *captureScope) (bool, TraceMessage)
+}
+
+// matchWithCaptures matches the message against the matcher, providing the
+// capture scope to the matcher if it is able to make use of it.
+func matchWithCaptures[T any](matcher Matcher[T], message T, scope *captureScope) bool {
+	if aware, ok := matcher.(captureAwareMatcher[T]); ok {
+		return aware.doesMatchCaptures(message, scope)
+	}
+
+	return matcher.DoesMatch(message)
+}
+
+// captureMatched binds any values the matcher wishes to capture from the accepted message, returning
+// them as formatted for the trace. Nil is returned if the matcher did not capture anything.
+func captureMatched[T any](matcher Matcher[T], message T, scope *captureScope) Captures {
+	if scope == nil {
+		return nil
+	}
+
+	if capturing, ok := matcher.(capturingMatcher[T]); ok {
+		return capturing.capture(message, scope)
+	}
+
+	return nil
+}
+
+// tryMatchCombiner delivers the message to the combiner, providing the
+// capture scope to the combiner if it is able to make use of it.
+func tryMatchCombiner[T any](combiner Combiner[T], message T, scope *captureScope) (bool, TraceMessage) {
+	if c, ok := combiner.(captureCombiner[T]); ok {
+		return c.tryMatchCaptures(message, scope)
+	}
+
+	return combiner.TryMatch(message)
+}
+
+// captureScope tracks the values bound over the lifetime of an expecter. Values bound
+// while processing the current message are additionally tracked as 'pending', so that
+// they can be attached to the result for that message alongside their formatted form.
+type captureScope struct {
+	bound     Captures
+	pending   Captures
+	formatted Captures
+	values    *valueFormatter
+}
+
+func newCaptureScope() *captureScope {
+	return &captureScope{bound: make(Captures), pending: make(Captures), formatted: make(Captures)}
+}
+
+// bind binds the value to the name provided. The formatted value is the
+// value as it should appear in the trace, errors and exports of the expecter.
+func (scope *captureScope) bind(name string, value any, formatted string) {
+	scope.bound[name] = value
+	scope.pending[name] = value
+	scope.formatted[name] = formatted
+}
+
+// formatter returns the formatter used to format the values within matcher explanations
+// and descriptions, which is the default formatter if the scope has none.
+func (scope *captureScope) formatter() *valueFormatter {
+	if scope == nil || scope.values == nil {
+		return defaultValueFormatter
+	}
+
+	return scope.values
+}
+
+func (scope *captureScope) lookup(name string) (any, bool) {
+	if scope == nil {
+		return nil, false
+	}
+
+	value, ok := scope.bound[name]
+	return value, ok
+}
+
+// flush returns the values bound since the last flush and their formatted
+// values, or nil if no values have been bound.
+func (scope *captureScope) flush() (Captures, Captures) {
+	if len(scope.pending) == 0 {
+		return nil, nil
+	}
+
+	pending, formatted := scope.pending, scope.formatted
+	scope.pending, scope.formatted = make(Captures), make(Captures)
+	return pending, formatted
+}
+
+// snapshot returns a copy of all values bound so far.
+func (scope *captureScope) snapshot() Captures {
+	out := make(Captures, len(scope.bound))
+	for name, value := range scope.bound {
+		out[name] = value
+	}
+
+	return out
+}
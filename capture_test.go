@@ -0,0 +1,147 @@
+package chanassert_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hbomb79/go-chanassert"
+)
+
+type job struct {
+	ID     int
+	Status string
+}
+
+func jobID(j job) any { return j.ID }
+
+func Test_Capture(t *testing.T) {
+	makeExpecter := func() (chan job, chanassert.Expecter[job]) {
+		ch := make(chan job, 10)
+		expecter := chanassert.NewChannelExpecter(ch).
+			Expect(chanassert.OneOf(
+				chanassert.Capture("jobID", jobID, chanassert.MatchStructPartial(job{Status: "started"})),
+			)).
+			Expect(chanassert.AllOf(
+				chanassert.MatchCaptured("jobID", jobID),
+				chanassert.MatchCaptured("jobID", jobID),
+			))
+
+		return ch, expecter
+	}
+
+	tests := []expecterTest[job]{
+		{
+			Summary:        "Later messages refer to captured value",
+			Messages:       []job{{ID: 4, Status: "started"}, {ID: 4, Status: "working"}, {ID: 4, Status: "done"}},
+			ExpectedErrors: []expectedError{},
+		},
+		{
+			Summary:        "Later messages refer to different value",
+			Messages:       []job{{ID: 4, Status: "started"}, {ID: 4, Status: "working"}, {ID: 5, Status: "done"}},
+			ExpectedErrors: []expectedError{rejectedError, unsatisfiedError, terminatedError},
+		},
+		{
+			Summary:        "Value not yet captured",
+			Messages:       []job{{ID: 4, Status: "working"}},
+			ExpectedErrors: []expectedError{rejectedError, unsatisfiedError, terminatedError},
+		},
+	}
+
+	runExpecterTests(t, makeExpecter, tests)
+}
+
+func Test_Capture_Rebind(t *testing.T) {
+	ch := make(chan job, 10)
+	expecter := chanassert.NewChannelExpecter(ch).
+		Expect(chanassert.ExactlyNOf(2,
+			chanassert.Capture("jobID", jobID, chanassert.MatchStructPartial(job{Status: "started"})),
+		)).
+		Expect(chanassert.OneOf(chanassert.MatchCaptured("jobID", jobID)))
+	expecter.Listen()
+
+	ch <- job{ID: 1, Status: "started"}
+	ch <- job{ID: 2, Status: "started"}
+	ch <- job{ID: 2, Status: "done"}
+	expecter.AssertSatisfied(t, time.Second)
+
+	if captured := expecter.Captures()["jobID"]; captured != 2 {
+		t.Errorf("expected most recent capture of jobID to be 2, got %v", captured)
+	}
+}
+
+func Test_Capture_Results(t *testing.T) {
+	ch := make(chan job, 10)
+	expecter := chanassert.NewChannelExpecter(ch).
+		Ignore(chanassert.MatchStructPartial(job{Status: "ignored"})).
+		Expect(chanassert.AllOf(
+			chanassert.Capture("jobID", jobID, chanassert.MatchStructPartial(job{Status: "started"})),
+			chanassert.MatchCapturedPredicate("jobID", func(j job, value any) bool {
+				id, ok := value.(int)
+				return ok && j.ID > id
+			}),
+		))
+	expecter.Listen()
+
+	ch <- job{ID: 9, Status: "started"}
+	ch <- job{ID: 9, Status: "ignored"}
+	ch <- job{ID: 10, Status: "child"}
+	expecter.AssertSatisfied(t, time.Second)
+
+	results := expecter.ProcessedMessages()
+	if len(results) != 3 {
+		t.Fatalf("expected 3 processed messages, got %d", len(results))
+	}
+
+	if captured := results[0].Captures["jobID"]; captured != 9 {
+		t.Errorf("expected message #0 to capture jobID=9, got %v", results[0].Captures)
+	}
+	if len(results[1].Captures) != 0 || len(results[2].Captures) != 0 {
+		t.Errorf("expected messages #1 and #2 to capture nothing, got %v and %v", results[1].Captures, results[2].Captures)
+	}
+
+	builder := &strings.Builder{}
+	expecter.FPrintTrace(builder)
+	for _, expected := range []string{"Message '{ID:9 Status:started}' - ACCEPTED (captured jobID=9):", "Captured jobID=9"} {
+		if !strings.Contains(builder.String(), expected) {
+			t.Errorf("expected trace to contain %q, but it did not:\n%s", expected, builder.String())
+		}
+	}
+}
+
+func Test_Capture_OutsideExpecter(t *testing.T) {
+	capture := chanassert.Capture("jobID", jobID, chanassert.MatchStructPartial(job{Status: "started"}))
+	if !capture.DoesMatch(job{ID: 1, Status: "started"}) {
+		t.Errorf("expected Capture to match using wrapped matcher")
+	}
+
+	if chanassert.MatchCaptured("jobID", jobID).DoesMatch(job{ID: 1}) {
+		t.Errorf("expected MatchCaptured to never match outside of an expecter")
+	}
+}
+
+func Test_Capture_MatcherAlgebra(t *testing.T) {
+	ch := make(chan job, 10)
+	expecter := chanassert.NewChannelExpecter(ch).
+		Expect(chanassert.OneOf(
+			chanassert.MatchAnyOf(
+				chanassert.Capture("jobID", jobID, chanassert.MatchStructPartial(job{Status: "started"})),
+				chanassert.Capture("jobID", jobID, chanassert.MatchStructPartial(job{Status: "queued"})),
+			),
+		)).
+		Expect(chanassert.OneOf(
+			chanassert.MatchAll(
+				chanassert.MatchCaptured("jobID", jobID),
+				chanassert.MatchNot(chanassert.MatchStructPartial(job{Status: "started"})),
+			),
+		))
+	expecter.Listen()
+
+	ch <- job{ID: 3, Status: "queued"}
+	ch <- job{ID: 3, Status: "done"}
+	expecter.AssertSatisfied(t, time.Second)
+
+	if captured := expecter.Captures()["jobID"]; captured != 3 {
+		t.Errorf("expected capture of jobID to be 3, got %v", captured)
+	}
+}
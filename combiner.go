@@ -3,6 +3,8 @@ package chanassert
 import (
 	"fmt"
 	"math"
+	"sort"
+	"sync"
 )
 
 // AllOf accepts a list of matchers. The returned combiner will
@@ -82,6 +84,17 @@ func ExactlyNOf[T any](n int, matchers ...Matcher[T]) *nCombiner[T] {
 	return &nCombiner[T]{mode: modeSum, matchers: matchers, min: n, max: n, counts: make(map[int]int)}
 }
 
+// MayOccur accepts a number, n, and a list of matchers. The returned combiner will
+// match up to N messages (in total) against the matchers provided. The combiner is optional,
+// meaning it is neutral to the layer which holds it: it will never hold the layer open, and
+// will never cause the layer to become satisfied. This is useful for messages which may
+// appear while a layer is active, but which are not required.
+//
+// A layer requires at least one non-optional combiner, and adding a layer of only optional combiners panics.
+func MayOccur[T any](n int, matchers ...Matcher[T]) *nCombiner[T] {
+	return &nCombiner[T]{mode: modeSum, matchers: matchers, min: 0, max: n, counts: make(map[int]int), optional: true}
+}
+
 type mode int
 
 const (
@@ -94,49 +107,54 @@ func (m mode) String() string {
 	return []string{"EACH", "ANY", "SUM"}[m]
 }
 
+// sumSubject describes the count of a combiner in 'sum' mode in its traces.
+const sumSubject = "SUM of all matched messages"
+
 type nCombiner[T any] struct {
+	CombinerBase
+
 	matchers []Matcher[T]
 	min      int
 	max      int
 	counts   map[int]int
 	mode     mode
 
-	// satisfied indicates whether this matcher can be considered 'done' by
-	// the layer which holds it. The matcher *may* be able to accept more messages (assuming
-	// it's not also saturated), however it doesn't *need* to do so.
-	satisfied bool
-
-	// saturated indicates whether the combiner is able to match against
-	// more messages. Depending on the mode, this value is set under different circumstances.
-	// Once saturated, any call to DoesMatch will return false.
-	saturated bool
+	// optional indicates that this combiner should be neutral to
+	// the layer which holds it (see MayOccur).
+	optional bool
 }
 
-func (nCombiner *nCombiner[T]) tryMatch(message T) (bool, TraceMessage) {
+func (nCombiner *nCombiner[T]) tryMatch(message T, scope *captureScope) (bool, TraceMessage) {
 	if nCombiner.saturated {
 		return false, newInfoTrace("Combiner is fully saturated, accepting no further messages")
 	}
 
 	attempts := make([]TraceMessage, 0)
 	for i, m := range nCombiner.matchers {
-		if m.DoesMatch(message) {
-			if nCombiner.mode == modeEach {
-				// If this matcher is saturated, then do not match against it anymore
-				if c := nCombiner.counts[i]; c >= nCombiner.max {
-					attempts = append(attempts, newInfoTrace(fmt.Sprintf("Matcher #%d REJECT: matcher has already matched maximum allowed messages", i)))
-					continue
-				}
+		if ok, reason := explainMatch(m, message, scope); !ok {
+			attempts = append(attempts, newErrorTrace(fmt.Sprintf("%s REJECT: %s", matcherLabel(i, m, scope.formatter()), reason)))
+			continue
+		}
+
+		if nCombiner.mode == modeEach {
+			// If this matcher is saturated, then do not match against it anymore
+			if c := nCombiner.counts[i]; c >= nCombiner.max {
+				attempts = append(attempts, newErrorTrace(fmt.Sprintf("%s REJECT: matcher has already matched maximum allowed messages", matcherLabel(i, m, scope.formatter()))))
+				continue
 			}
+		}
 
-			nCombiner.counts[i]++
-			attempts = append(attempts, newInfoTrace(fmt.Sprintf("Matcher #%d ACCEPT", i)))
-			return true, newInfoTrace(fmt.Sprintf("Combiner matched on matcher #%d", i), attempts...)
-		} else {
-			attempts = append(attempts, newInfoTrace(fmt.Sprintf("Matcher #%d REJECT: no match", i)))
+		nCombiner.counts[i]++
+		accepted := newInfoTrace(fmt.Sprintf("%s ACCEPT", matcherLabel(i, m, scope.formatter())))
+		if captured := captureMatched(m, message, scope); len(captured) > 0 {
+			accepted.Nested = append(accepted.Nested, newInfoTrace(fmt.Sprintf("Captured %s", captured)))
 		}
+
+		attempts = append(attempts, accepted)
+		return true, newInfoTrace(fmt.Sprintf("Combiner matched on matcher #%d", i), attempts...)
 	}
 
-	return false, newInfoTrace("Combiner failed match message", attempts...)
+	return false, newErrorTrace("Combiner failed match message", attempts...)
 }
 
 // TryMatch attempts to match the given message against
@@ -145,26 +163,24 @@ func (nCombiner *nCombiner[T]) tryMatch(message T) (bool, TraceMessage) {
 // TraceMessage is returned alongside (regardless of the match being successful or not)
 // which contains information about the messages handling.
 func (nCombiner *nCombiner[T]) TryMatch(message T) (bool, TraceMessage) {
-	ok, trace := nCombiner.tryMatch(message)
+	return nCombiner.tryMatchCaptures(message, nil)
+}
+
+func (nCombiner *nCombiner[T]) tryMatchCaptures(message T, scope *captureScope) (bool, TraceMessage) {
+	ok, trace := nCombiner.tryMatch(message, scope)
 
-	modeTrace := newInfoTrace(fmt.Sprintf("%s mode with minimum of %d and maximum of %d", nCombiner.mode, nCombiner.min, nCombiner.max))
+	mode := fmt.Sprintf("%s mode with minimum of %d and maximum of %d", nCombiner.mode, nCombiner.min, nCombiner.max)
 	satisfiedTrace := nCombiner.updateSatisifed()
 	saturatedTrace := nCombiner.updateSaturation()
 
-	status := newInfoTrace("Combiner status", modeTrace, satisfiedTrace, saturatedTrace, nCombiner.matcherCountsTrace())
+	status := NewCombinerStatusTrace(mode, satisfiedTrace, saturatedTrace, nCombiner.matcherCountsTrace())
 
 	trace.Nested = append(trace.Nested, status)
 	return ok, trace
 }
 
 func (nCombiner *nCombiner[T]) updateSaturation() TraceMessage {
-	generateTrace := func(isSaturated bool, reason string) TraceMessage {
-		if isSaturated {
-			return newInfoTrace("Saturated", newDebugTrace(reason))
-		}
-
-		return newInfoTrace("NOT saturated", newDebugTrace(reason))
-	}
+	generateTrace := newSaturatedTrace
 
 	//exhaustive:enforce
 	switch nCombiner.mode {
@@ -184,7 +200,8 @@ func (nCombiner *nCombiner[T]) updateSaturation() TraceMessage {
 		}
 
 		notSaturated := make(idxList, 0)
-		for idx, c := range nCombiner.counts {
+		for _, idx := range nCombiner.matchedIndices() {
+			c := nCombiner.counts[idx]
 			if c < nCombiner.max {
 				notSaturated = append(notSaturated, idx)
 			}
@@ -198,7 +215,8 @@ func (nCombiner *nCombiner[T]) updateSaturation() TraceMessage {
 		return generateTrace(false, fmt.Sprintf("EACH matcher needs to match at least %d messages, but matchers %v have not", nCombiner.max, notSaturated))
 	case modeAny:
 		// In 'any' mode, the combiner is saturated when any ONE matcher has consumed the maximum
-		for idx, c := range nCombiner.counts {
+		for _, idx := range nCombiner.matchedIndices() {
+			c := nCombiner.counts[idx]
 			if c >= nCombiner.max {
 				nCombiner.saturated = true
 
@@ -210,26 +228,14 @@ func (nCombiner *nCombiner[T]) updateSaturation() TraceMessage {
 		return generateTrace(false, fmt.Sprintf("ANY matcher needs to match %d messages, but none have", nCombiner.max))
 	case modeSum:
 		// In 'sum' mode, the combiner is saturated when the sum of matched messages has reached the maximum
-		sum := nCombiner.sumMatches()
-		nCombiner.saturated = sum >= nCombiner.max
-		if nCombiner.saturated {
-			return generateTrace(true, fmt.Sprintf("SUM of all matched messages (%d) has met maximum (%d) messages", sum, nCombiner.max))
-		}
-
-		return generateTrace(false, fmt.Sprintf("SUM of all matched messages (%d) must be at least %d", sum, nCombiner.max))
+		return nCombiner.UpdateSaturatedCount(sumSubject, nCombiner.sumMatches(), nCombiner.max)
 	}
 
 	panic("unreachable")
 }
 
 func (nCombiner *nCombiner[T]) updateSatisifed() TraceMessage {
-	generateTrace := func(isSatisfied bool, reason string) TraceMessage {
-		if isSatisfied {
-			return newInfoTrace("Satisfied", newDebugTrace(reason))
-		} else {
-			return newInfoTrace("NOT satisfied", newDebugTrace(reason))
-		}
-	}
+	generateTrace := newSatisfiedTrace
 
 	//exhaustive:enforce
 	switch nCombiner.mode {
@@ -248,7 +254,8 @@ func (nCombiner *nCombiner[T]) updateSatisifed() TraceMessage {
 		}
 
 		notSatisfied := make(idxList, 0)
-		for idx, c := range nCombiner.counts {
+		for _, idx := range nCombiner.matchedIndices() {
+			c := nCombiner.counts[idx]
 			if c < nCombiner.min || c > nCombiner.max {
 				notSatisfied = append(notSatisfied, idx)
 			}
@@ -261,7 +268,8 @@ func (nCombiner *nCombiner[T]) updateSatisifed() TraceMessage {
 
 		return generateTrace(false, fmt.Sprintf("ALL matchers needs to match at least %d messages, but matchers %v have not", nCombiner.min, notSatisfied))
 	case modeAny:
-		for idx, c := range nCombiner.counts {
+		for _, idx := range nCombiner.matchedIndices() {
+			c := nCombiner.counts[idx]
 			if c >= nCombiner.min && c <= nCombiner.max {
 				// At least one of the matchers are between min and max. Satisfied!
 				nCombiner.satisfied = true
@@ -273,30 +281,31 @@ func (nCombiner *nCombiner[T]) updateSatisifed() TraceMessage {
 		nCombiner.satisfied = false
 		return generateTrace(false, fmt.Sprintf("ANY matcher needs to match at least %d messages, but none have", nCombiner.min))
 	case modeSum:
-		count := nCombiner.sumMatches()
-		nCombiner.satisfied = count >= nCombiner.min && count <= nCombiner.max
-
-		if nCombiner.satisfied {
-			return generateTrace(true, fmt.Sprintf("SUM of all matched messages (%d) has met minimum (%d) messages", count, nCombiner.min))
-		}
-
-		return generateTrace(false, fmt.Sprintf("SUM of all matched messages (%d) must meet %d messages", count, nCombiner.min))
+		return nCombiner.UpdateSatisfiedCount(sumSubject, nCombiner.sumMatches(), nCombiner.min, nCombiner.max)
 	}
 
 	panic("unreachable")
 }
 
 func (nCombiner *nCombiner[T]) matcherCountsTrace() TraceMessage {
-	details := make([]TraceMessage, 0, len(nCombiner.matchers))
+	counts := make([]int, len(nCombiner.matchers))
 	for k := range nCombiner.matchers {
-		if count, ok := nCombiner.counts[k]; ok {
-			details = append(details, newInfoTrace(fmt.Sprintf("Matcher #%d => %d message(s)", k, count)))
-		} else {
-			details = append(details, newInfoTrace(fmt.Sprintf("Matcher #%d => 0 messages", k)))
-		}
+		counts[k] = nCombiner.counts[k]
 	}
 
-	return newDebugTrace("Matcher counts", details...)
+	return NewMatcherCountsTrace(counts)
+}
+
+// matchedIndices returns the indices of the matchers which have matched at least one
+// message, in ascending order, so that the traces built from the counts are stable.
+func (nCombiner *nCombiner[T]) matchedIndices() []int {
+	indices := make([]int, 0, len(nCombiner.counts))
+	for idx := range nCombiner.counts {
+		indices = append(indices, idx)
+	}
+	sort.Ints(indices)
+
+	return indices
 }
 
 func (nCombiner *nCombiner[T]) sumMatches() int {
@@ -308,6 +317,196 @@ func (nCombiner *nCombiner[T]) sumMatches() int {
 	return count
 }
 
-func (nCombiner *nCombiner[T]) IsSatisfied() bool {
-	return nCombiner.satisfied
+func (nCombiner *nCombiner[T]) isOptional() bool {
+	return nCombiner.optional
+}
+
+// Named names this combiner, so that it is referred to by name (rather
+// than by its index) in traces and errors.
+func (nCombiner *nCombiner[T]) Named(name string) *nCombiner[T] {
+	nCombiner.SetName(name)
+	return nCombiner
+}
+
+// Describe describes the bounds of this combiner, and each of its matchers.
+func (nCombiner *nCombiner[T]) Describe() string {
+	return nCombiner.describeWith(defaultValueFormatter)
+}
+
+func (nCombiner *nCombiner[T]) describeWith(values *valueFormatter) string {
+	return describeList(nCombiner.summary(), describeMatchers(nCombiner.matchers, values))
+}
+
+func (nCombiner *nCombiner[T]) describeTree(values *valueFormatter) TraceMessage {
+	return newInfoTrace(nCombiner.summary(), describeMatcherTree(nCombiner.matchers, values)...)
+}
+
+func (nCombiner *nCombiner[T]) summary() string {
+	bounds := describeBounds(nCombiner.min, nCombiner.max)
+
+	var summary string
+	//exhaustive:enforce
+	switch nCombiner.mode {
+	case modeEach:
+		summary = fmt.Sprintf("%s message(s) matching each of", bounds)
+	case modeAny:
+		summary = fmt.Sprintf("%s message(s) matching any one of", bounds)
+	case modeSum:
+		summary = fmt.Sprintf("%s message(s) in total matching any of", bounds)
+	}
+
+	if nCombiner.optional {
+		return "optionally, " + summary
+	}
+
+	return summary
+}
+
+func (nCombiner *nCombiner[T]) report(values *valueFormatter) CombinerReport {
+	report := CombinerReport{
+		Description: nCombiner.summary(),
+		Mode:        nCombiner.mode.String(),
+		Min:         nCombiner.min,
+		Max:         nCombiner.max,
+		Matchers:    reportMatchers(nCombiner.matchers, values, func(idx int) int { return nCombiner.counts[idx] }),
+	}
+
+	//exhaustive:enforce
+	switch nCombiner.mode {
+	case modeEach:
+		for idx, m := range nCombiner.matchers {
+			if c := nCombiner.counts[idx]; c < nCombiner.min {
+				report.Shortfall = append(report.Shortfall, fmt.Sprintf("needs %d more of %s", nCombiner.min-c, label("matcher", idx, m)))
+			}
+		}
+	case modeAny:
+		best := 0
+		for _, c := range nCombiner.counts {
+			best = max(best, c)
+		}
+
+		report.Shortfall = append(report.Shortfall, fmt.Sprintf("needs %d more of any one matcher", nCombiner.min-best))
+	case modeSum:
+		report.Shortfall = append(report.Shortfall, fmt.Sprintf("needs %d more message(s) matching any matcher", nCombiner.min-nCombiner.sumMatches()))
+	}
+
+	return report
+}
+
+// Reset discards all messages matched by this combiner, allowing it to be
+// used by another layer once the expecter using it has finished.
+func (nCombiner *nCombiner[T]) Reset() {
+	nCombiner.counts = make(map[int]int)
+	nCombiner.ResetBase()
+}
+
+// claimant is implemented by the owner of the claims on combiners (i.e. an expecter).
+type claimant interface {
+	// isListening returns true while the claimant is matching messages against its combiners.
+	isListening() bool
+}
+
+// ownership records which expecter has claimed a combiner. Combiners accumulate
+// state as they match messages, and so sharing one between layers (or expecters)
+// would silently corrupt the results of both. A claim only prevents other expecters
+// from claiming the combiner while the expecter holding it is listening: once that
+// expecter has finished (or if it never started), the combiner is reset and claimed
+// by the next expecter to use it, so that each expecter starts with a fresh combiner.
+type ownership struct {
+	mu    sync.Mutex
+	owner claimant
+}
+
+// claim claims the combiner for the owner provided, returning false if another owner which is
+// listening holds the claim, or if the owner already holds the claim and again is false (i.e. the
+// combiner is used twice by the same owner). If the claim was taken from another owner, stale is
+// true, as the combiner may hold state from that owner and so must be reset.
+func (o *ownership) claim(owner claimant, again bool) (claimed bool, stale bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	switch {
+	case o.owner == owner:
+		return again, false
+	case o.owner != nil && o.owner.isListening():
+		return false, false
+	}
+
+	stale = o.owner != nil
+	o.owner = owner
+	return true, stale
+}
+
+// release releases the claim of the owner provided, if it holds the claim.
+func (o *ownership) release(owner claimant) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.owner == owner {
+		o.owner = nil
+	}
+}
+
+// claimableCombiner is implemented by combiners which are
+// able to detect being used by more than one layer.
+type claimableCombiner interface {
+	claim(owner claimant, again bool) (claimed bool, stale bool)
+	release(owner claimant)
+}
+
+// resettableCombiner is implemented by combiners which are able to
+// return to their initial state, so that they can be reused (see [Combiner]).
+type resettableCombiner interface {
+	Reset()
+}
+
+// resetCombiner resets the combiner provided, if it is able to be reset.
+func resetCombiner[T any](combiner Combiner[T]) {
+	if c, ok := combiner.(resettableCombiner); ok {
+		c.Reset()
+	}
+}
+
+// claimCombiner claims the combiner provided on behalf of the owner (see [ownership.claim]),
+// resetting the combiner if it was claimed from another owner. Combiners which do not track
+// ownership can always be claimed.
+func claimCombiner[T any](combiner Combiner[T], owner claimant, again bool) bool {
+	c, ok := combiner.(claimableCombiner)
+	if !ok {
+		return true
+	}
+
+	claimed, stale := c.claim(owner, again)
+	if stale {
+		resetCombiner(combiner)
+	}
+
+	return claimed
+}
+
+// releaseCombiner releases a combiner previously claimed by [claimCombiner].
+func releaseCombiner[T any](combiner Combiner[T], owner claimant) {
+	if c, ok := combiner.(claimableCombiner); ok {
+		c.release(owner)
+	}
+}
+
+// newSatisfiedTrace returns the trace used by combiners to report
+// whether they are satisfied, with the reason as debug output.
+func newSatisfiedTrace(isSatisfied bool, reason string) TraceMessage {
+	if isSatisfied {
+		return newInfoTrace("Satisfied", newDebugTrace(reason))
+	}
+
+	return newInfoTrace("NOT satisfied", newDebugTrace(reason))
+}
+
+// newSaturatedTrace returns the trace used by combiners to report
+// whether they are saturated, with the reason as debug output.
+func newSaturatedTrace(isSaturated bool, reason string) TraceMessage {
+	if isSaturated {
+		return newInfoTrace("Saturated", newDebugTrace(reason))
+	}
+
+	return newInfoTrace("NOT saturated", newDebugTrace(reason))
 }
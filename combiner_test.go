@@ -26,22 +26,29 @@ func runCombinerTests[T any](t *testing.T, makeCombiner func() chanassert.Combin
 			t.Parallel()
 
 			matcher := makeCombiner()
-			for i, msg := range test.messages {
-				shouldPass := test.expected[i]
-				shouldBeSatisfied := test.satisfied[i]
-				res, _ := matcher.TryMatch(msg)
-
-				if shouldPass && !res {
-					t.Errorf("Combiner REJECTED message '%v' (#%d), but it was expected to accept", msg, i)
-				} else if !shouldPass && res {
-					t.Errorf("Combiner ACCEPTED message '%v' (#%d), but it was expected to reject it", msg, i)
+			for run := 1; run <= 2; run++ {
+				// Replaying the messages after a reset must produce identical results
+				if run > 1 {
+					matcher.(interface{ Reset() }).Reset()
 				}
 
-				isSatisfied := matcher.IsSatisfied()
-				if shouldBeSatisfied && !isSatisfied {
-					t.Errorf("Combiner NOT SATISFIED (after message '%v' (#%d)), but it was expected to be", msg, i)
-				} else if !shouldBeSatisfied && isSatisfied {
-					t.Errorf("Combiner SATISFIED (after message '%v' (#%d)), but it was expected to be unsatisfied", msg, i)
+				for i, msg := range test.messages {
+					shouldPass := test.expected[i]
+					shouldBeSatisfied := test.satisfied[i]
+					res, _ := matcher.TryMatch(msg)
+
+					if shouldPass && !res {
+						t.Errorf("Combiner REJECTED message '%v' (#%d, run #%d), but it was expected to accept", msg, i, run)
+					} else if !shouldPass && res {
+						t.Errorf("Combiner ACCEPTED message '%v' (#%d, run #%d), but it was expected to reject it", msg, i, run)
+					}
+
+					isSatisfied := matcher.IsSatisfied()
+					if shouldBeSatisfied && !isSatisfied {
+						t.Errorf("Combiner NOT SATISFIED (after message '%v' (#%d, run #%d)), but it was expected to be", msg, i, run)
+					} else if !shouldBeSatisfied && isSatisfied {
+						t.Errorf("Combiner SATISFIED (after message '%v' (#%d, run #%d)), but it was expected to be unsatisfied", msg, i, run)
+					}
 				}
 			}
 		})
@@ -550,3 +557,34 @@ func Test_ExactlyNOfAny(t *testing.T) {
 
 	runCombinerTests(t, makeCombiner, tests)
 }
+
+func Test_MayOccur(t *testing.T) {
+	makeCombiner := func() chanassert.Combiner[string] {
+		return chanassert.MayOccur(2,
+			chanassert.MatchEqual("hello"),
+			chanassert.MatchEqual("world"),
+		)
+	}
+	tests := []combinerTest[string]{
+		{
+			summary:   "Up to N messages",
+			messages:  []string{"hello", "world"},
+			expected:  []bool{true, true},
+			satisfied: []bool{true, true},
+		},
+		{
+			summary:   "More than N messages",
+			messages:  []string{"world", "world", "hello"},
+			expected:  []bool{true, true, false},
+			satisfied: []bool{true, true, true},
+		},
+		{
+			summary:   "Non-matching messages",
+			messages:  []string{"foo", "hello"},
+			expected:  []bool{false, true},
+			satisfied: []bool{true, true},
+		},
+	}
+
+	runCombinerTests(t, makeCombiner, tests)
+}
@@ -0,0 +1,314 @@
+package chanassert
+
+import (
+	"fmt"
+	"slices"
+)
+
+// AllCombiners accepts a list of combiners, and returns a combiner which will become
+// satisfied once ALL of the combiners provided are satisfied. Messages are offered to each of
+// the combiners in turn, until one of them accepts it. This allows combiners to be composed
+// together in to a tree, rather than requiring a new layer.
+//
+// As within a layer, optional combiners (see [Optional]) are neutral, and so at
+// least one of the combiners must not be optional.
+func AllCombiners[T any](combiners ...Combiner[T]) *compositeCombiner[T] {
+	return newCompositeCombiner(ModeAnd, combiners)
+}
+
+// AnyCombiner accepts a list of combiners, and returns a combiner which will become
+// satisfied once ANY of the combiners provided are satisfied. Messages are offered to each of
+// the combiners in turn, until one of them accepts it. Once one of the combiners is both satisfied
+// and saturated, this combiner will accept no further messages.
+//
+// As within a layer, optional combiners (see [Optional]) are neutral, and so at
+// least one of the combiners must not be optional.
+func AnyCombiner[T any](combiners ...Combiner[T]) *compositeCombiner[T] {
+	return newCompositeCombiner(ModeOr, combiners)
+}
+
+func newCompositeCombiner[T any](mode LayerMode, combiners []Combiner[T]) *compositeCombiner[T] {
+	if len(combiners) > 0 && !slices.ContainsFunc(combiners, func(c Combiner[T]) bool { return !isOptional(c) }) {
+		panic(fmt.Sprintf("%q mode combiner has no required combiners (every combiner is optional)", mode))
+	}
+
+	return &compositeCombiner[T]{mode: mode, combiners: combiners}
+}
+
+// Optional wraps the combiner provided such that it is ALWAYS considered satisfied,
+// regardless of how many messages it has matched. Messages are still delivered to the
+// wrapped combiner, and so it will continue to enforce its maximum.
+//
+// When used within a layer (or within [AllCombiners] or [AnyCombiner]), an optional combiner
+// is neutral: it never holds the layer open, and never causes the layer to become satisfied.
+// A layer requires at least one non-optional combiner, and adding a layer of only optional
+// combiners panics.
+func Optional[T any](combiner Combiner[T]) *optionalCombiner[T] {
+	return &optionalCombiner[T]{combiner: combiner}
+}
+
+type compositeCombiner[T any] struct {
+	CombinerBase
+
+	combiners []Combiner[T]
+	mode      LayerMode
+}
+
+func (composite *compositeCombiner[T]) tryMatch(message T, scope *captureScope) (bool, TraceMessage) {
+	if composite.saturated {
+		return false, newInfoTrace("Combiner is fully saturated, accepting no further messages")
+	}
+
+	traces := make([]TraceMessage, 0, len(composite.combiners))
+	for idx, combiner := range composite.combiners {
+		ok, trace := tryMatchCombiner(combiner, message, scope)
+		trace.Message = label("Combiner", idx, combiner) + ": " + trace.Message
+
+		traces = append(traces, trace)
+		if ok {
+			return true, newInfoTrace("Combiner matched on "+label("combiner", idx, combiner), traces...)
+		}
+	}
+
+	return false, newErrorTrace("Combiner failed match message", traces...)
+}
+
+// TryMatch offers the message to each of the combiners contained
+// within this combiner, in order, until one of them accepts it. The
+// traces of the nested combiners are nested within the returned trace.
+func (composite *compositeCombiner[T]) TryMatch(message T) (bool, TraceMessage) {
+	return composite.tryMatchCaptures(message, nil)
+}
+
+func (composite *compositeCombiner[T]) tryMatchCaptures(message T, scope *captureScope) (bool, TraceMessage) {
+	ok, trace := composite.tryMatch(message, scope)
+
+	mode := fmt.Sprintf("%s mode with %d combiners", composite.mode, len(composite.combiners))
+	satisfiedTrace := composite.updateSatisfied()
+	saturatedTrace := composite.updateSaturation()
+
+	status := NewCombinerStatusTrace(mode, satisfiedTrace, saturatedTrace)
+
+	trace.Nested = append(trace.Nested, status)
+	return ok, trace
+}
+
+func (composite *compositeCombiner[T]) updateSatisfied() TraceMessage {
+	// Optional combiners are neutral, as they are within a layer
+	satisfied, notSatisfied, _ := partitionCombiners(composite.combiners)
+	if composite.mode == ModeAnd {
+		composite.satisfied = len(notSatisfied) == 0
+	} else {
+		composite.satisfied = len(satisfied) > 0
+	}
+
+	return newSatisfiedTrace(composite.satisfied, NewCombinersStatusTrace(composite.mode, composite.combiners).Message)
+}
+
+func (composite *compositeCombiner[T]) updateSaturation() TraceMessage {
+	saturated := make(idxList, 0)
+	for idx, combiner := range composite.combiners {
+		if !isSaturated(combiner) {
+			continue
+		}
+
+		if composite.mode == ModeOr && combiner.IsSatisfied() && !isOptional(combiner) {
+			// In 'Or' mode, a combiner which is done (satisfied and saturated) means
+			// this combiner cannot make any further progress
+			composite.saturated = true
+			return newSaturatedTrace(true, fmt.Sprintf("%s is satisfied and has matched maximum allowed messages", label("Combiner", idx, combiner)))
+		}
+
+		saturated = append(saturated, idx)
+	}
+
+	composite.saturated = len(saturated) == len(composite.combiners)
+	if composite.saturated {
+		return newSaturatedTrace(true, "ALL combiners have matched maximum allowed messages")
+	}
+
+	return newSaturatedTrace(false, fmt.Sprintf("ALL combiners need to match maximum allowed messages, but only combiners %s have", saturated))
+}
+
+func (composite *compositeCombiner[T]) report(values *valueFormatter) CombinerReport {
+	report := CombinerReport{
+		Description: composite.summary(),
+		Mode:        composite.mode.String(),
+		Combiners:   reportCombiners(composite.combiners, values),
+	}
+
+	if composite.mode == ModeAnd {
+		report.Shortfall = unsatisfiedCombiners(composite.combiners)
+	} else {
+		report.Shortfall = []string{"needs any one of the combiners to be satisfied"}
+	}
+
+	return report
+}
+
+// Named names this combiner, so that it is referred to by name (rather
+// than by its index) in traces and errors.
+func (composite *compositeCombiner[T]) Named(name string) *compositeCombiner[T] {
+	composite.SetName(name)
+	return composite
+}
+
+// Describe describes this combiner, and each of the combiners contained within it.
+func (composite *compositeCombiner[T]) Describe() string {
+	return composite.describeWith(defaultValueFormatter)
+}
+
+func (composite *compositeCombiner[T]) describeWith(values *valueFormatter) string {
+	return describeList(composite.summary(), describeCombiners(composite.combiners, values))
+}
+
+func (composite *compositeCombiner[T]) describeTree(values *valueFormatter) TraceMessage {
+	return newInfoTrace(composite.summary(), describeCombinerTree(composite.combiners, values)...)
+}
+
+func (composite *compositeCombiner[T]) summary() string {
+	if composite.mode == ModeAnd {
+		return "all of the combiners"
+	}
+
+	return "any of the combiners"
+}
+
+// Reset resets this combiner, and each of the combiners contained within it, allowing
+// them to be used by another layer once the expecter using them has finished.
+func (composite *compositeCombiner[T]) Reset() {
+	for _, combiner := range composite.combiners {
+		resetCombiner(combiner)
+	}
+
+	composite.ResetBase()
+}
+
+// claim claims this combiner along with each of the combiners contained
+// within it, as a nested combiner is just as stateful as its parent.
+func (composite *compositeCombiner[T]) claim(owner claimant, again bool) (bool, bool) {
+	claimed, stale := composite.ownership.claim(owner, again)
+	if !claimed {
+		return false, false
+	}
+
+	for idx, combiner := range composite.combiners {
+		if !claimCombiner(combiner, owner, again) {
+			for _, claimed := range composite.combiners[:idx] {
+				releaseCombiner(claimed, owner)
+			}
+
+			composite.ownership.release(owner)
+			return false, false
+		}
+	}
+
+	return true, stale
+}
+
+func (composite *compositeCombiner[T]) release(owner claimant) {
+	for _, combiner := range composite.combiners {
+		releaseCombiner(combiner, owner)
+	}
+
+	composite.ownership.release(owner)
+}
+
+type optionalCombiner[T any] struct {
+	CombinerBase
+
+	combiner Combiner[T]
+}
+
+// TryMatch delivers the message to the wrapped combiner.
+func (optional *optionalCombiner[T]) TryMatch(message T) (bool, TraceMessage) {
+	return optional.tryMatchCaptures(message, nil)
+}
+
+func (optional *optionalCombiner[T]) tryMatchCaptures(message T, scope *captureScope) (bool, TraceMessage) {
+	ok, trace := tryMatchCombiner(optional.combiner, message, scope)
+	trace.Message = "(optional) " + trace.Message
+
+	return ok, trace
+}
+
+// IsSatisfied always returns true, as an optional
+// combiner never needs to match any messages.
+func (optional *optionalCombiner[T]) IsSatisfied() bool {
+	return true
+}
+
+func (optional *optionalCombiner[T]) IsSaturated() bool {
+	return isSaturated(optional.combiner)
+}
+
+func (optional *optionalCombiner[T]) isOptional() bool {
+	return true
+}
+
+// Named names this combiner, so that it is referred to by name (rather
+// than by its index) in traces and errors.
+func (optional *optionalCombiner[T]) Named(name string) *optionalCombiner[T] {
+	optional.SetName(name)
+	return optional
+}
+
+// Name returns the name given to this combiner, or the name of
+// the wrapped combiner if this combiner has not been named.
+func (optional *optionalCombiner[T]) Name() string {
+	if name := optional.CombinerBase.Name(); name != "" {
+		return name
+	}
+
+	return nameOf(optional.combiner)
+}
+
+func (optional *optionalCombiner[T]) Describe() string {
+	return optional.describeWith(defaultValueFormatter)
+}
+
+func (optional *optionalCombiner[T]) describeWith(values *valueFormatter) string {
+	return "optionally, " + describeWith(optional.combiner, values)
+}
+
+// report returns the report of the wrapped combiner. As optional combiners
+// are always satisfied, any shortfall of the wrapped combiner is discarded.
+func (optional *optionalCombiner[T]) report(values *valueFormatter) CombinerReport {
+	report := reportCombiner(0, optional.combiner, values)
+	report.Description = "optionally, " + report.Description
+	report.Shortfall = nil
+
+	return report
+}
+
+func (optional *optionalCombiner[T]) describeTree(values *valueFormatter) TraceMessage {
+	tree := describeTree(optional.combiner, values)
+	tree.Message = "optionally, " + tree.Message
+
+	return tree
+}
+
+// Reset resets the wrapped combiner.
+func (optional *optionalCombiner[T]) Reset() {
+	resetCombiner(optional.combiner)
+	optional.ResetBase()
+}
+
+func (optional *optionalCombiner[T]) claim(owner claimant, again bool) (bool, bool) {
+	claimed, stale := optional.ownership.claim(owner, again)
+	if !claimed {
+		return false, false
+	}
+
+	if !claimCombiner(optional.combiner, owner, again) {
+		optional.ownership.release(owner)
+		return false, false
+	}
+
+	return true, stale
+}
+
+func (optional *optionalCombiner[T]) release(owner claimant) {
+	releaseCombiner(optional.combiner, owner)
+	optional.ownership.release(owner)
+}
@@ -0,0 +1,229 @@
+package chanassert_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hbomb79/go-chanassert"
+)
+
+func Test_AllCombiners(t *testing.T) {
+	makeCombiner := func() chanassert.Combiner[string] {
+		return chanassert.AllCombiners(
+			chanassert.AllOf(chanassert.MatchEqual("a"), chanassert.MatchEqual("b")),
+			chanassert.ExactlyNOf(2, chanassert.MatchEqual("c")),
+		)
+	}
+
+	tests := []combinerTest[string]{
+		{
+			summary:   "All combiners satisfied",
+			messages:  []string{"a", "c", "b", "c"},
+			expected:  []bool{true, true, true, true},
+			satisfied: []bool{false, false, false, true},
+		},
+		{
+			summary:   "Saturated once all combiners saturated",
+			messages:  []string{"a", "b", "c", "c", "a", "c"},
+			expected:  []bool{true, true, true, true, false, false},
+			satisfied: []bool{false, false, false, true, true, true},
+		},
+		{
+			summary:   "Only one combiner satisfied",
+			messages:  []string{"a", "b", "c", "d"},
+			expected:  []bool{true, true, true, false},
+			satisfied: []bool{false, false, false, false},
+		},
+	}
+
+	runCombinerTests(t, makeCombiner, tests)
+}
+
+func Test_AnyCombiner(t *testing.T) {
+	makeCombiner := func() chanassert.Combiner[string] {
+		return chanassert.AnyCombiner(
+			chanassert.AllOf(chanassert.MatchEqual("a"), chanassert.MatchEqual("b")),
+			chanassert.ExactlyNOf(3, chanassert.MatchEqual("c")),
+		)
+	}
+
+	tests := []combinerTest[string]{
+		{
+			summary:   "First combiner satisfied",
+			messages:  []string{"b", "a"},
+			expected:  []bool{true, true},
+			satisfied: []bool{false, true},
+		},
+		{
+			summary:   "Second combiner satisfied",
+			messages:  []string{"c", "c", "c"},
+			expected:  []bool{true, true, true},
+			satisfied: []bool{false, false, true},
+		},
+		{
+			summary:   "Saturated once a combiner is satisfied and saturated",
+			messages:  []string{"c", "a", "b", "c"},
+			expected:  []bool{true, true, true, false},
+			satisfied: []bool{false, false, true, true},
+		},
+		{
+			summary:   "Neither combiner satisfied",
+			messages:  []string{"a", "c", "c"},
+			expected:  []bool{true, true, true},
+			satisfied: []bool{false, false, false},
+		},
+	}
+
+	runCombinerTests(t, makeCombiner, tests)
+}
+
+func Test_AnyCombiner_Optional(t *testing.T) {
+	makeCombiner := func() chanassert.Combiner[string] {
+		return chanassert.AnyCombiner(
+			chanassert.OneOf(chanassert.MatchEqual("a")),
+			chanassert.Optional[string](chanassert.OneOf(chanassert.MatchEqual("b"))),
+		)
+	}
+
+	tests := []combinerTest[string]{
+		{
+			summary:   "Optional combiner does not satisfy",
+			messages:  []string{"b"},
+			expected:  []bool{true},
+			satisfied: []bool{false},
+		},
+		{
+			summary:   "Saturated optional combiner does not saturate",
+			messages:  []string{"b", "a"},
+			expected:  []bool{true, true},
+			satisfied: []bool{false, true},
+		},
+	}
+
+	runCombinerTests(t, makeCombiner, tests)
+}
+
+func Test_CompositeCombiner_OnlyOptional(t *testing.T) {
+	for _, build := range []func(...chanassert.Combiner[string]) chanassert.Combiner[string]{
+		func(c ...chanassert.Combiner[string]) chanassert.Combiner[string] {
+			return chanassert.AllCombiners(c...)
+		},
+		func(c ...chanassert.Combiner[string]) chanassert.Combiner[string] {
+			return chanassert.AnyCombiner(c...)
+		},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected composite combiner of only optional combiners to panic")
+				}
+			}()
+
+			build(chanassert.Optional[string](chanassert.OneOf(chanassert.MatchEqual("a"))))
+		}()
+	}
+}
+
+func Test_Optional(t *testing.T) {
+	makeCombiner := func() chanassert.Combiner[string] {
+		return chanassert.Optional[string](chanassert.ExactlyNOf(2, chanassert.MatchEqual("a")))
+	}
+
+	tests := []combinerTest[string]{
+		{
+			summary:   "No messages",
+			messages:  []string{"b"},
+			expected:  []bool{false},
+			satisfied: []bool{true},
+		},
+		{
+			summary:   "Maximum enforced",
+			messages:  []string{"a", "a", "a"},
+			expected:  []bool{true, true, false},
+			satisfied: []bool{true, true, true},
+		},
+	}
+
+	runCombinerTests(t, makeCombiner, tests)
+}
+
+func Test_Optional_Named(t *testing.T) {
+	inner := chanassert.OneOf(chanassert.MatchEqual("a")).Named("inner")
+	if name := chanassert.Optional[string](inner).Name(); name != "inner" {
+		t.Errorf("expected unnamed optional combiner to use the name of the wrapped combiner, got %q", name)
+	}
+
+	if name := chanassert.Optional[string](inner).Named("outer").Name(); name != "outer" {
+		t.Errorf("expected named optional combiner to use its own name, got %q", name)
+	}
+}
+
+func Test_NestedCombiners(t *testing.T) {
+	makeExpecter := func() (chan string, chanassert.Expecter[string]) {
+		ch := make(chan string, 10)
+		exp := chanassert.NewChannelExpecter(ch).Expect(
+			chanassert.AnyCombiner(
+				chanassert.AllOf(chanassert.MatchEqual("a"), chanassert.MatchEqual("b")),
+				chanassert.ExactlyNOf(3, chanassert.MatchEqual("c")),
+			),
+			chanassert.AllCombiners(
+				chanassert.OneOf(chanassert.MatchEqual("x")),
+				chanassert.Optional[string](chanassert.OneOf(chanassert.MatchEqual("y"))),
+			),
+		)
+
+		return ch, exp
+	}
+
+	tests := []expecterTest[string]{
+		{
+			Summary:  "First branch with optional message",
+			Messages: []string{"a", "y", "b", "x"},
+		},
+		{
+			Summary:  "Second branch without optional message",
+			Messages: []string{"c", "x", "c", "c"},
+		},
+		{
+			Summary:        "Optional message delivered twice",
+			Messages:       []string{"c", "y", "y", "c", "c", "x"},
+			ExpectedErrors: []expectedError{rejectedError},
+		},
+		{
+			Summary:        "Neither branch satisfied",
+			Messages:       []string{"a", "c", "x"},
+			ExpectedErrors: []expectedError{unsatisfiedError, terminatedError},
+		},
+	}
+
+	runExpecterTests(t, makeExpecter, tests)
+}
+
+func Test_NestedCombiners_Trace(t *testing.T) {
+	combiner := chanassert.AnyCombiner(
+		chanassert.AllOf(chanassert.MatchEqual("a")),
+		chanassert.Optional[string](chanassert.OneOf(chanassert.MatchEqual("b"))),
+	)
+
+	_, trace := combiner.TryMatch("b")
+	builder := &strings.Builder{}
+	trace.PrintTrace(builder, false, 0)
+
+	expected := []string{
+		"  - Combiner matched on combiner #1",
+		"    * Combiner #0: Combiner failed match message",
+		"    * Combiner #1: (optional) Combiner matched on matcher #0",
+		"      + Matcher #0 (equals \"b\") ACCEPT",
+		"    * Combiner status",
+		"      + OR mode with 2 combiners",
+		// The optional combiner is neutral, and so neither satisfies nor saturates the composite
+		"      + NOT satisfied",
+		"      + NOT saturated",
+	}
+
+	for _, line := range expected {
+		if !strings.Contains(builder.String(), line+"\n") {
+			t.Errorf("expected trace to contain line %q, but it did not:\n%s", line, builder.String())
+		}
+	}
+}
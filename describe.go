@@ -0,0 +1,168 @@
+package chanassert
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Describer is an optional interface which may be implemented by matchers, combiners
+// and layers in order to describe themselves in words (e.g. `equals "hello"`). All of the
+// built-in matchers, combiners and layers implement this interface.
+//
+// Descriptions of matchers are included in traces (e.g. `Matcher #0 (equals "hello") ACCEPT`),
+// and [Expecter.Describe] renders the descriptions of all layers as a tree.
+type Describer interface {
+	Describe() string
+}
+
+// Namer is an optional interface which may be implemented by matchers, combiners and layers
+// which have been given a name by the user. Named values are referred to by their name in traces
+// and errors (e.g. "layer 'handshake'") rather than by their position (e.g. "layer #0"), which
+// makes it possible to trace them back to the code which created them.
+//
+// The built-in combiners are named using their Named method, matchers using [NamedMatcher],
+// and layers using [Expecter.Named].
+type Namer interface {
+	Name() string
+}
+
+// describeTreer is implemented by combiners and layers which are able
+// to describe themselves, and each of their children, as a tree.
+type describeTreer interface {
+	describeTree(values *valueFormatter) TraceMessage
+}
+
+// formattingDescriber is implemented by the built-in matchers, combiners and layers, whose descriptions
+// include values (or the descriptions of matchers). The values are formatted using the formatter provided,
+// so that fields redacted by the formatter of the expecter (see [Expecter.FormatMessages]) are redacted.
+type formattingDescriber interface {
+	describeWith(values *valueFormatter) string
+}
+
+// describe returns the description of the value provided if it
+// implements [Describer], otherwise the name of its type.
+func describe(v any) string {
+	if describer, ok := v.(Describer); ok {
+		return describer.Describe()
+	}
+
+	return fmt.Sprintf("%T", v)
+}
+
+// describeWith returns the description of the value provided, formatting
+// any values it contains using the formatter provided.
+func describeWith(v any, values *valueFormatter) string {
+	if describer, ok := v.(formattingDescriber); ok {
+		return describer.describeWith(values)
+	}
+
+	return describe(v)
+}
+
+// describeTree returns the description tree of the value provided if it
+// is able to provide one, otherwise its description without any children.
+func describeTree(v any, values *valueFormatter) TraceMessage {
+	if treer, ok := v.(describeTreer); ok {
+		return treer.describeTree(values)
+	}
+
+	return newInfoTrace(describeWith(v, values))
+}
+
+// describeList joins the summary provided with the descriptions of the
+// children, for use by combiners which describe themselves on a single line.
+func describeList(summary string, children []string) string {
+	return fmt.Sprintf("%s [%s]", summary, strings.Join(children, ", "))
+}
+
+// describeBounds describes the minimum and maximum provided, as used
+// by the built-in combiners (e.g. "between 1 and 2").
+func describeBounds(min int, max int) string {
+	switch {
+	case min == max:
+		return fmt.Sprintf("exactly %d", min)
+	case max == math.MaxInt:
+		return fmt.Sprintf("at least %d", min)
+	default:
+		return fmt.Sprintf("between %d and %d", min, max)
+	}
+}
+
+func describeMatchers[T any](matchers []Matcher[T], values *valueFormatter) []string {
+	descriptions := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		descriptions = append(descriptions, describeWith(m, values))
+	}
+
+	return descriptions
+}
+
+func describeMatcherTree[T any](matchers []Matcher[T], values *valueFormatter) []TraceMessage {
+	nodes := make([]TraceMessage, 0, len(matchers))
+	for idx, m := range matchers {
+		nodes = append(nodes, newInfoTrace(fmt.Sprintf("%s: %s", label("Matcher", idx, m), describeWith(m, values))))
+	}
+
+	return nodes
+}
+
+func describeCombiners[T any](combiners []Combiner[T], values *valueFormatter) []string {
+	descriptions := make([]string, 0, len(combiners))
+	for _, c := range combiners {
+		descriptions = append(descriptions, describeWith(c, values))
+	}
+
+	return descriptions
+}
+
+func describeCombinerTree[T any](combiners []Combiner[T], values *valueFormatter) []TraceMessage {
+	nodes := make([]TraceMessage, 0, len(combiners))
+	for idx, c := range combiners {
+		node := describeTree(c, values)
+		node.Message = fmt.Sprintf("%s: %s", label("Combiner", idx, c), node.Message)
+		nodes = append(nodes, node)
+	}
+
+	return nodes
+}
+
+// matcherLabel returns the label used to refer to the matcher in traces,
+// including its description if the matcher implements [Describer].
+func matcherLabel[T any](idx int, matcher Matcher[T], values *valueFormatter) string {
+	return label("Matcher", idx, matcher) + describeSuffix(matcher, values)
+}
+
+// describeSuffix returns the description of the value provided wrapped in
+// parentheses, or an empty string if the value does not implement [Describer].
+func describeSuffix(v any, values *valueFormatter) string {
+	if _, ok := v.(Describer); ok {
+		return fmt.Sprintf(" (%s)", describeWith(v, values))
+	}
+
+	return ""
+}
+
+// nameOf returns the name of the value provided, or an empty
+// string if it does not implement [Namer] or has not been named.
+func nameOf(v any) string {
+	if namer, ok := v.(Namer); ok {
+		return namer.Name()
+	}
+
+	return ""
+}
+
+// label returns how the value at the index provided is referred to in traces and
+// errors: by its name if it has one (e.g. "layer 'handshake'"), otherwise by its index.
+func label(noun string, idx int, v any) string {
+	return nameLabel(noun, idx, nameOf(v))
+}
+
+func nameLabel(noun string, idx int, name string) string {
+	if name != "" {
+		return fmt.Sprintf("%s '%s'", noun, name)
+	}
+
+	return fmt.Sprintf("%s #%d", noun, idx)
+}
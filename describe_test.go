@@ -0,0 +1,104 @@
+package chanassert_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hbomb79/go-chanassert"
+)
+
+func Test_Describe(t *testing.T) {
+	type response struct {
+		Status int
+		Body   string
+	}
+
+	tests := []struct {
+		summary   string
+		describer chanassert.Describer
+		expected  string
+	}{
+		{"MatchEqual", chanassert.MatchEqual("hello"), `equals "hello"`},
+		{"MatchStringContains", chanassert.MatchStringContains("ell"), `contains "ell"`},
+		{"MatchStruct", chanassert.MatchStruct(response{200, "ok"}), `deeply equals {Status:200 Body:ok}`},
+		{"MatchStructPartial", chanassert.MatchStructPartial(response{Status: 200}), `has fields {Status=200}`},
+		{
+			"MatchStructFields",
+			chanassert.MatchStructFields[response](map[string]any{"Body": "ok", "Status": func(int) bool { return true }}),
+			`has fields {Body="ok", Status=<predicate>}`,
+		},
+		{"MatchPredicate", chanassert.MatchPredicate(func(string) bool { return true }), `matches predicate`},
+		{
+			"Matcher algebra",
+			chanassert.MatchAll(chanassert.MatchAnything[string](), chanassert.MatchNot(chanassert.MatchAnyOf(chanassert.MatchEqual("a")))),
+			`all of (anything, not (any of (equals "a")))`,
+		},
+		{"Capture", chanassert.Capture("id", jobID, chanassert.MatchStructPartial(job{Status: "started"})), `has fields {Status="started"}, capturing "id"`},
+		{"MatchCaptured", chanassert.MatchCaptured("id", jobID), `equals captured "id"`},
+		{"OneOf", chanassert.OneOf(chanassert.MatchEqual("a"), chanassert.MatchEqual("b")), `exactly 1 message(s) in total matching any of [equals "a", equals "b"]`},
+		{"AtLeastNOfEach", chanassert.AtLeastNOfEach(2, chanassert.MatchEqual("a")), `at least 2 message(s) matching each of [equals "a"]`},
+		{"BetweenNOfAny", chanassert.BetweenNOfAny(1, 3, chanassert.MatchEqual("a")), `between 1 and 3 message(s) matching any one of [equals "a"]`},
+		{"MayOccur", chanassert.MayOccur(2, chanassert.MatchEqual("a")), `optionally, between 0 and 2 message(s) in total matching any of [equals "a"]`},
+		{"InOrder", chanassert.InOrder(chanassert.MatchEqual("a"), chanassert.MatchEqual("b")), `exactly 1 message(s) matching each of, in order [equals "a", equals "b"]`},
+		{
+			"ExactlyNOfEachKey",
+			chanassert.ExactlyNOfEachKey(1, workerKey, chanassert.MatchStringContains(":done")).WithKeys("w1", "w2"),
+			`exactly 1 message(s) for each key in [w1 w2], matching any of [contains ":done"]`,
+		},
+		{"AtLeastNDistinct", chanassert.AtLeastNDistinct(3, workerKey, chanassert.MatchAnything[string]()), `at least 3 distinct key(s), matching any of [anything]`},
+		{
+			"Composite",
+			chanassert.AnyCombiner[string](
+				chanassert.AllOf(chanassert.MatchEqual("a")),
+				chanassert.Optional[string](chanassert.OneOf(chanassert.MatchEqual("b"))),
+			),
+			`any of the combiners [exactly 1 message(s) matching each of [equals "a"], optionally, exactly 1 message(s) in total matching any of [equals "b"]]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.summary, func(t *testing.T) {
+			t.Parallel()
+
+			if actual := test.describer.Describe(); actual != test.expected {
+				t.Errorf("expected description %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_Expecter_Describe(t *testing.T) {
+	expecter := chanassert.NewChannelExpecter(make(chan string)).
+		Ignore(chanassert.MatchStringContains("heartbeat")).
+		Expect(
+			chanassert.AllOf(chanassert.MatchEqual("hello"), chanassert.MatchEqual("world")),
+			chanassert.AllCombiners[string](
+				chanassert.OneOf(chanassert.MatchEqual("a")),
+				chanassert.Optional[string](chanassert.OneOf(chanassert.MatchEqual("b"))),
+			),
+		).
+		ExpectAnyTimeout(time.Second, chanassert.BetweenNOf(1, 2, chanassert.MatchEqual("done")))
+
+	expected := strings.Join([]string{
+		`  - Layer #0: ALL combiners must be satisfied ("AND" mode)`,
+		`    * Combiner #0: exactly 1 message(s) matching each of`,
+		`      + Matcher #0: equals "hello"`,
+		`      + Matcher #1: equals "world"`,
+		`    * Combiner #1: all of the combiners`,
+		`      + Combiner #0: exactly 1 message(s) in total matching any of`,
+		`        > Matcher #0: equals "a"`,
+		`      + Combiner #1: optionally, exactly 1 message(s) in total matching any of`,
+		`        > Matcher #0: equals "b"`,
+		`  - Layer #1: ANY combiner must be satisfied ("OR" mode, within 1s)`,
+		`    * Combiner #0: between 1 and 2 message(s) in total matching any of`,
+		`      + Matcher #0: equals "done"`,
+		`  - Ignoring messages matching`,
+		`    * Matcher #0: contains "heartbeat"`,
+		``,
+	}, "\n")
+
+	if actual := expecter.Describe(); actual != expected {
+		t.Errorf("expected description:\n%s\nbut got:\n%s", expected, actual)
+	}
+}
@@ -0,0 +1,258 @@
+package chanassert
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DiagramFormat is the language a diagram is generated in by [Expecter.Diagram].
+type DiagramFormat int
+
+const (
+	// DiagramMermaid generates a mermaid flowchart, which is rendered by (among others) GitHub markdown.
+	DiagramMermaid DiagramFormat = iota
+	// DiagramDOT generates a Graphviz DOT digraph.
+	DiagramDOT
+)
+
+// The classes of nodes in a diagram annotated with the outcome of the run.
+const (
+	diagramSatisfied   = "satisfied"
+	diagramUnsatisfied = "unsatisfied"
+	diagramUnreached   = "unreached"
+)
+
+var diagramColors = map[string][2]string{
+	diagramSatisfied:   {"#c8e6c9", "#2e7d32"},
+	diagramUnsatisfied: {"#ffcdd2", "#c62828"},
+	diagramUnreached:   {"#eeeeee", "#9e9e9e"},
+}
+
+type diagramConfig struct {
+	outcome bool
+}
+
+// DiagramOption configures the diagram generated by [Expecter.Diagram].
+type DiagramOption func(*diagramConfig)
+
+// WithOutcome annotates the diagram with the outcome of the run: whether each layer and combiner was
+// satisfied (or, for layers, never reached), the number of messages accepted and rejected by each layer,
+// and the number of messages consumed by each matcher. This should only be used once the expecter has finished.
+func WithOutcome() DiagramOption {
+	return func(config *diagramConfig) {
+		config.outcome = true
+	}
+}
+
+// diagramNode is a node of a diagram, which is connected to each of its children.
+type diagramNode struct {
+	id       string
+	label    string
+	notes    []string
+	class    string
+	children []*diagramNode
+}
+
+// newDiagramNode returns the node for the description tree provided, and each of its children.
+func newDiagramNode(id string, tree TraceMessage) *diagramNode {
+	node := &diagramNode{id: id, label: tree.Message}
+	for idx, child := range tree.Nested {
+		node.children = append(node.children, newDiagramNode(fmt.Sprintf("%s_%d", id, idx), child))
+	}
+
+	return node
+}
+
+// annotateCombiner annotates the node of a combiner (and its children) with the report provided.
+// Built-in combiners describe their children in the same order as they report them.
+func annotateCombiner(node *diagramNode, report CombinerReport) {
+	node.notes = append(node.notes, report.status())
+	node.class = diagramUnsatisfied
+	if report.Satisfied {
+		node.class = diagramSatisfied
+	}
+
+	for _, key := range report.Keys {
+		node.notes = append(node.notes, fmt.Sprintf("key %s => %s", key.Key, formatCount(key.Count)))
+	}
+
+	switch {
+	case len(report.Combiners) > 0 && len(report.Combiners) == len(node.children):
+		for idx, child := range node.children {
+			annotateCombiner(child, report.Combiners[idx])
+		}
+	case len(report.Matchers) > 0 && len(report.Matchers) == len(node.children):
+		for idx, child := range node.children {
+			child.notes = append(child.notes, formatCount(report.Matchers[idx].Count))
+		}
+	}
+}
+
+// Diagram generates a diagram of the structure of this expecter in the format provided (see [DiagramFormat]):
+// each layer (in the order they are selected), its combiners and their matchers, and the ignore matchers. Pass
+// [WithOutcome] to annotate the diagram with the outcome of the run. Nodes are labelled in the same way as
+// [Expecter.Describe], and so matchers and combiners which do not implement [Describer] are labelled using their type.
+func (exp *expecter[T]) Diagram(format DiagramFormat, opts ...DiagramOption) string {
+	config := diagramConfig{}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	accepted := make([]int, len(exp.expectLayers))
+	rejected := make([]int, len(exp.expectLayers))
+	ignored := 0
+	for _, result := range exp.results {
+		switch {
+		case result.Status == Ignored:
+			ignored++
+		case result.Status == Accepted:
+			accepted[result.LayerIdx]++
+		default:
+			rejected[result.LayerIdx]++
+		}
+	}
+
+	layers := make([]*diagramNode, 0, len(exp.expectLayers))
+	for idx, layer := range exp.expectLayers {
+		node := newDiagramNode(fmt.Sprintf("L%d", idx), exp.describeLayer(idx))
+		if config.outcome {
+			switch {
+			case layer.IsSatisfied():
+				node.class = diagramSatisfied
+			case idx > exp.currentLayerIndex:
+				node.class = diagramUnreached
+			default:
+				node.class = diagramUnsatisfied
+			}
+
+			node.notes = append(node.notes, node.class, fmt.Sprintf("%s accepted, %s rejected", formatCount(accepted[idx]), formatCount(rejected[idx])))
+			if report := exp.reportLayer(idx); report != nil && node.class != diagramUnreached && len(report.Combiners) == len(node.children) {
+				for combinerIdx, child := range node.children {
+					annotateCombiner(child, report.Combiners[combinerIdx])
+				}
+			}
+		}
+
+		layers = append(layers, node)
+	}
+
+	var ignore *diagramNode
+	if len(exp.ignoreMatchers) > 0 {
+		ignore = newDiagramNode("I", newInfoTrace("Ignoring messages matching", describeMatcherTree(exp.ignoreMatchers, exp.captures.formatter())...))
+		if config.outcome {
+			ignore.notes = append(ignore.notes, formatCount(ignored)+" ignored")
+		}
+	}
+
+	//exhaustive:enforce
+	switch format {
+	case DiagramMermaid:
+		return mermaidDiagram(layers, ignore)
+	case DiagramDOT:
+		return dotDiagram(layers, ignore)
+	}
+
+	panic(fmt.Sprintf("unknown diagram format %d", format))
+}
+
+// walkDiagram calls the function provided for the node, and each of its descendants,
+// along with the parent of the node (or nil for the node provided).
+func walkDiagram(node *diagramNode, parent *diagramNode, fn func(node *diagramNode, parent *diagramNode)) {
+	fn(node, parent)
+	for _, child := range node.children {
+		walkDiagram(child, node, fn)
+	}
+}
+
+var (
+	mermaidEntityPattern = regexp.MustCompile(`#(\w+;)`)
+	mermaidEscaper       = strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;")
+)
+
+// mermaidEscape escapes the label provided for use within a quoted mermaid label. Only the '#'
+// characters which would otherwise be read as entity codes (e.g. "#quot;") are escaped.
+func mermaidEscape(label string) string {
+	return mermaidEscaper.Replace(mermaidEntityPattern.ReplaceAllString(label, "#35;$1"))
+}
+
+func mermaidDiagram(layers []*diagramNode, ignore *diagramNode) string {
+	builder := &strings.Builder{}
+	builder.WriteString("flowchart TD\n")
+
+	classes := make(map[string][]string)
+	write := func(node *diagramNode, parent *diagramNode) {
+		label := mermaidEscape(node.label)
+		for _, note := range node.notes {
+			label += "<br/>" + mermaidEscape(note)
+		}
+
+		fmt.Fprintf(builder, "    %s[\"%s\"]\n", node.id, label)
+		if parent != nil {
+			fmt.Fprintf(builder, "    %s --> %s\n", parent.id, node.id)
+		}
+
+		if node.class != "" {
+			classes[node.class] = append(classes[node.class], node.id)
+		}
+	}
+
+	for idx, layer := range layers {
+		walkDiagram(layer, nil, write)
+		if idx > 0 {
+			fmt.Fprintf(builder, "    %s ==>|then| %s\n", layers[idx-1].id, layer.id)
+		}
+	}
+
+	if ignore != nil {
+		walkDiagram(ignore, nil, write)
+	}
+
+	for _, class := range []string{diagramSatisfied, diagramUnsatisfied, diagramUnreached} {
+		if ids, ok := classes[class]; ok {
+			fmt.Fprintf(builder, "    classDef %s fill:%s,stroke:%s\n", class, diagramColors[class][0], diagramColors[class][1])
+			fmt.Fprintf(builder, "    class %s %s\n", strings.Join(ids, ","), class)
+		}
+	}
+
+	return builder.String()
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+func dotDiagram(layers []*diagramNode, ignore *diagramNode) string {
+	builder := &strings.Builder{}
+	builder.WriteString("digraph expecter {\n")
+	builder.WriteString("    node [shape=box];\n")
+
+	write := func(node *diagramNode, parent *diagramNode) {
+		lines := make([]string, 0, len(node.notes)+1)
+		for _, line := range append([]string{node.label}, node.notes...) {
+			lines = append(lines, dotEscaper.Replace(line))
+		}
+
+		attributes := fmt.Sprintf("label=\"%s\"", strings.Join(lines, `\n`))
+		if colors, ok := diagramColors[node.class]; ok {
+			attributes += fmt.Sprintf(", style=filled, fillcolor=\"%s\", color=\"%s\"", colors[0], colors[1])
+		}
+
+		fmt.Fprintf(builder, "    %s [%s];\n", node.id, attributes)
+		if parent != nil {
+			fmt.Fprintf(builder, "    %s -> %s;\n", parent.id, node.id)
+		}
+	}
+
+	for idx, layer := range layers {
+		walkDiagram(layer, nil, write)
+		if idx > 0 {
+			fmt.Fprintf(builder, "    %s -> %s [label=\"then\", style=bold];\n", layers[idx-1].id, layer.id)
+		}
+	}
+
+	if ignore != nil {
+		walkDiagram(ignore, nil, write)
+	}
+
+	builder.WriteString("}\n")
+	return builder.String()
+}
@@ -0,0 +1,120 @@
+package chanassert_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hbomb79/go-chanassert"
+)
+
+func makeDiagramExpecter() chanassert.Expecter[string] {
+	return chanassert.NewChannelExpecter(make(chan string, 10)).
+		Ignore(chanassert.MatchEqual("noise")).
+		Expect(chanassert.OneOf(chanassert.MatchEqual("hello"))).Named("greeting").
+		Expect(chanassert.ExactlyNOf(2, chanassert.MatchEqual("world"), chanassert.MatchEqual("#x;")))
+}
+
+func Test_Diagram(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   chanassert.DiagramFormat
+		expected []string
+	}{
+		{
+			name:   "Mermaid",
+			format: chanassert.DiagramMermaid,
+			expected: []string{
+				"flowchart TD",
+				`    L0["Layer 'greeting': ALL combiners must be satisfied (#quot;AND#quot; mode)"]`,
+				`    L0_0["Combiner #0: exactly 1 message(s) in total matching any of"]`,
+				`    L0 --> L0_0`,
+				`    L0_0_0["Matcher #0: equals #quot;hello#quot;"]`,
+				`    L0_0 --> L0_0_0`,
+				`    L1["Layer #1: ALL combiners must be satisfied (#quot;AND#quot; mode)"]`,
+				`    L1_0["Combiner #0: exactly 2 message(s) in total matching any of"]`,
+				`    L1 --> L1_0`,
+				`    L1_0_0["Matcher #0: equals #quot;world#quot;"]`,
+				`    L1_0 --> L1_0_0`,
+				`    L1_0_1["Matcher #1: equals #quot;#35;x;#quot;"]`,
+				`    L1_0 --> L1_0_1`,
+				`    L0 ==>|then| L1`,
+				`    I["Ignoring messages matching"]`,
+				`    I_0["Matcher #0: equals #quot;noise#quot;"]`,
+				`    I --> I_0`,
+			},
+		},
+		{
+			name:   "DOT",
+			format: chanassert.DiagramDOT,
+			expected: []string{
+				"digraph expecter {",
+				"    node [shape=box];",
+				`    L0 [label="Layer 'greeting': ALL combiners must be satisfied (\"AND\" mode)"];`,
+				`    L0_0 [label="Combiner #0: exactly 1 message(s) in total matching any of"];`,
+				`    L0 -> L0_0;`,
+				`    L0_0_0 [label="Matcher #0: equals \"hello\""];`,
+				`    L0_0 -> L0_0_0;`,
+				`    L1 [label="Layer #1: ALL combiners must be satisfied (\"AND\" mode)"];`,
+				`    L1_0 [label="Combiner #0: exactly 2 message(s) in total matching any of"];`,
+				`    L1 -> L1_0;`,
+				`    L1_0_0 [label="Matcher #0: equals \"world\""];`,
+				`    L1_0 -> L1_0_0;`,
+				`    L1_0_1 [label="Matcher #1: equals \"#x;\""];`,
+				`    L1_0 -> L1_0_1;`,
+				`    L0 -> L1 [label="then", style=bold];`,
+				`    I [label="Ignoring messages matching"];`,
+				`    I_0 [label="Matcher #0: equals \"noise\""];`,
+				`    I -> I_0;`,
+				"}",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diagram := makeDiagramExpecter().Diagram(test.format)
+			if expected := strings.Join(test.expected, "\n") + "\n"; diagram != expected {
+				t.Errorf("unexpected diagram, expected:\n%s\ngot:\n%s", expected, diagram)
+			}
+		})
+	}
+}
+
+func Test_Diagram_WithOutcome(t *testing.T) {
+	ch := make(chan string, 10)
+	exp := chanassert.NewChannelExpecter(ch).
+		Ignore(chanassert.MatchEqual("noise")).
+		Expect(chanassert.OneOf(chanassert.MatchEqual("hello"))).Named("greeting").
+		Expect(chanassert.ExactlyNOf(2, chanassert.MatchEqual("world"), chanassert.MatchEqual("x"))).
+		Expect(chanassert.OneOf(chanassert.MatchEqual("bye")))
+
+	exp.Listen()
+	for _, m := range []string{"noise", "world", "hello", "world"} {
+		ch <- m
+	}
+	exp.AwaitSatisfied(time.Millisecond * 100)
+
+	mermaid := exp.Diagram(chanassert.DiagramMermaid, chanassert.WithOutcome())
+	for _, expected := range []string{
+		`L0["Layer 'greeting': ALL combiners must be satisfied (#quot;AND#quot; mode)<br/>satisfied<br/>1 message(s) accepted, 1 message(s) rejected"]`,
+		`L1_0["Combiner #0: exactly 2 message(s) in total matching any of<br/>NOT satisfied, NOT saturated"]`,
+		`L1_0_0["Matcher #0: equals #quot;world#quot;<br/>1 message(s)"]`,
+		`L1_0_1["Matcher #1: equals #quot;x#quot;<br/>0 messages"]`,
+		`L2["Layer #2: ALL combiners must be satisfied (#quot;AND#quot; mode)<br/>unreached<br/>0 messages accepted, 0 messages rejected"]`,
+		`I["Ignoring messages matching<br/>1 message(s) ignored"]`,
+		"class L0,L0_0 satisfied",
+		"class L1,L1_0 unsatisfied",
+		"class L2 unreached",
+	} {
+		if !strings.Contains(mermaid, expected) {
+			t.Errorf("expected mermaid diagram to contain %q, got:\n%s", expected, mermaid)
+		}
+	}
+
+	dot := exp.Diagram(chanassert.DiagramDOT, chanassert.WithOutcome())
+	expected := `L1 [label="Layer #1: ALL combiners must be satisfied (\"AND\" mode)\nunsatisfied\n1 message(s) accepted, 0 messages rejected", style=filled, fillcolor="#ffcdd2", color="#c62828"];`
+	if !strings.Contains(dot, expected) {
+		t.Errorf("expected DOT diagram to contain %q, got:\n%s", expected, dot)
+	}
+}
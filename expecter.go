@@ -1,6 +1,13 @@
 // chanassert offers an expressive and dynamic
 // way to assert that messages over a channel arrive
 // as expected.
+//
+// Golden traces (see [AssertTraceGolden]) are updated by setting the CHANASSERT_UPDATE
+// environment variable (see [GoldenUpdateEnv]), or by passing [UpdateGolden], rather than
+// by a -chanassert.update flag. This is deliberate: the package would have to register such
+// a flag on the global flag set when it is imported, adding it to every binary which imports
+// chanassert (not only test binaries), and panicking if any other package registered a flag
+// of the same name. Suites which prefer a flag can define their own and pass it to UpdateGolden.
 package chanassert
 
 import (
@@ -8,11 +15,27 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// Combiner groups the matchers of a layer, and decides which messages are accepted
+// by the layer (see TryMatch) and when the layer is satisfied (see IsSatisfied).
+//
+// Combiners may also implement the following methods, which are used when present:
+//   - IsSaturated() bool, which must return true if the combiner is unable to accept any
+//     further messages (e.g. as it has matched its maximum). Combiners which do not
+//     implement it are never considered saturated.
+//   - Reset(), which must return the combiner to the state it was in when it was constructed,
+//     discarding any messages it has matched. Combiners are stateful, and so a combiner can only
+//     be used by one layer at a time: the built-in combiners (and those embedding [CombinerBase])
+//     will panic if they are added to a second layer of the same expecter, or to a layer of another
+//     expecter while the expecter using them is listening. Once that expecter has finished (or if
+//     it never started listening), the combiner is Reset when it is next added to a layer, so that
+//     each expecter starts with a fresh combiner.
 type Combiner[T any] interface {
 	TryMatch(t T) (bool, TraceMessage)
 	IsSatisfied() bool
@@ -22,6 +45,11 @@ type Combiner[T any] interface {
 // a specific matcher which can be selected by the expecter at any one time. Each
 // time a new ExpectMatcher is provided to the Expecter, it is placed in it's own
 // layer. This is what gives the expecter the "expect this THEN this THEN this" pattern.
+//
+// Layers may also implement Finish() error, which is called once the expecter has finished
+// listening, exactly once for every layer which was selected (see Begin) during the run. This
+// gives a layer the opportunity to perform any final validation. If a non-nil error is returned,
+// the expecter will report it as a [LayerError] (each time AwaitSatisfied is called).
 type Layer[T any] interface {
 	// TryMatch is called by the Expecter on a layer when a message is received. This method
 	// must return true if the message is valid for the layer, otherwise false. A 'Valid' message is
@@ -39,17 +67,27 @@ type Layer[T any] interface {
 	Begin()
 }
 
+// finishingLayer is implemented by layers which perform final
+// validation once the expecter has finished listening (see [Layer]).
+type finishingLayer interface {
+	Finish() error
+}
+
 type RejectionError[T any] struct {
 	MessageNum    int
 	MessageResult MessageResult[T]
+
+	// NearMiss describes the matcher (in any layer) which came closest to
+	// accepting the message, or nil if no matcher came close.
+	NearMiss *NearMiss
 }
 
 func (e RejectionError[T]) Error() string {
 	return fmt.Sprintf(
-		"message #%d (%v) was unexpected by layer #%d",
+		"message #%d (%s) was unexpected by %s",
 		e.MessageNum,
-		e.MessageResult.Message,
-		e.MessageResult.LayerIdx,
+		e.MessageResult.formatted(),
+		nameLabel("layer", e.MessageResult.LayerIdx, e.MessageResult.LayerName),
 	)
 }
 
@@ -63,10 +101,40 @@ func (e TerminatedError) Error() string {
 
 type UnsatisfiedError struct {
 	ActiveLayerIdx int
+
+	// ActiveLayerName is the name of the active layer (see [Expecter.Named]),
+	// or an empty string if it was not named.
+	ActiveLayerName string
+
+	// Report is a breakdown of the state of the active layer (and each of its combiners) once
+	// the expecter finished, which explains why it was not satisfied. Report is nil for
+	// custom layers (see [ExpectLayer]).
+	Report *LayerReport
 }
 
 func (e UnsatisfiedError) Error() string {
-	return fmt.Sprintf("active layer (layer #%d) never became satisfied", e.ActiveLayerIdx)
+	return fmt.Sprintf("active layer (%s) never became satisfied", nameLabel("layer", e.ActiveLayerIdx, e.ActiveLayerName))
+}
+
+// LayerError is reported by AwaitSatisfied when a layer which was selected during the run
+// fails its final validation, i.e. its Finish method (see [Layer]) returned an error.
+type LayerError struct {
+	LayerIdx int
+
+	// LayerName is the name of the layer (see [Expecter.Named]),
+	// or an empty string if it was not named.
+	LayerName string
+
+	// Err is the error returned by the Finish method of the layer.
+	Err error
+}
+
+func (e LayerError) Error() string {
+	return fmt.Sprintf("%s failed final validation: %s", nameLabel("layer", e.LayerIdx, e.LayerName), e.Err)
+}
+
+func (e LayerError) Unwrap() error {
+	return e.Err
 }
 
 type Errors []error
@@ -89,17 +157,29 @@ type Expecter[T any] interface {
 	Expect(combiners ...Combiner[T]) Expecter[T]
 	ExpectAnyTimeout(timeout time.Duration, combiners ...Combiner[T]) Expecter[T]
 	ExpectTimeout(timeout time.Duration, combiners ...Combiner[T]) Expecter[T]
+	ExpectQuorum(k int, combiners ...Combiner[T]) Expecter[T]
+	ExpectExclusive(combiners ...Combiner[T]) Expecter[T]
+	ExpectLayer(layer Layer[T]) Expecter[T]
+	Named(name string) Expecter[T]
 
 	Ignore(matchers ...Matcher[T]) Expecter[T]
 
-	AssertSatisfied(t TestingT, timeout time.Duration)
+	AssertSatisfied(t TestingT, timeout time.Duration, opts ...TraceOption)
 	AwaitSatisfied(timeout time.Duration) Errors
 
-	PrintTrace()
-	FPrintTrace(w io.Writer)
+	PrintTrace(opts ...TraceOption)
+	FPrintTrace(w io.Writer, opts ...TraceOption)
 	ProcessedMessages() []MessageResult[T]
+	Captures() Captures
+	Describe() string
+	Diagram(format DiagramFormat, opts ...DiagramOption) string
+	Export(opts ...ExportOption) Export[T]
+	WriteJSON(w io.Writer, opts ...ExportOption) error
 
 	Debug() Expecter[T]
+	Verbosity(level TraceLevel) Expecter[T]
+	FormatMessages(formatter Formatter[T]) Expecter[T]
+	Normalize() Expecter[T]
 
 	Listen()
 }
@@ -111,8 +191,26 @@ type expecter[T any] struct {
 	expectLayers      []Layer[T]
 	wg                *sync.WaitGroup
 	closeChan         chan struct{}
+	listening         atomic.Bool
 	results           []MessageResult[T]
+	captures          *captureScope
 	debug             bool
+	verbosity         *TraceLevel
+	normalize         bool
+	format            Formatter[T]
+
+	// startedAt is when the expecter began listening, and finishedAt when it finished (or was
+	// terminated). layerSpans holds when each layer was selected, and when it became satisfied.
+	startedAt  time.Time
+	finishedAt time.Time
+	layerSpans []layerSpan
+
+	// finishErrs holds the error returned by Finish for each layer which was selected, so
+	// that Finish is called exactly once per layer regardless of calls to AwaitSatisfied.
+	finishErrs map[int]error
+
+	// errs holds the errors reported by the most recent call to AwaitSatisfied.
+	errs Errors
 }
 
 func NewChannelExpecter[T any](channel chan T) *expecter[T] {
@@ -124,6 +222,8 @@ func NewChannelExpecter[T any](channel chan T) *expecter[T] {
 		closeChan:         make(chan struct{}, 1),
 		wg:                &sync.WaitGroup{},
 		results:           make([]MessageResult[T], 0),
+		captures:          newCaptureScope(),
+		format:            MessageFormatter[T](),
 	}
 }
 
@@ -136,16 +236,36 @@ func (exp *expecter[T]) Ignore(matchers ...Matcher[T]) Expecter[T] {
 	return exp
 }
 
-func (exp *expecter[T]) addLayer(mode LayerMode, timeout *time.Duration, combiners []Combiner[T]) Expecter[T] {
+// addLayer adds a layer of the combiners provided. This method will panic if every combiner is optional (see
+// [MayOccur] and [Optional]), as such a layer would never hold itself open, nor ever become satisfied.
+func (exp *expecter[T]) addLayer(mode LayerMode, timeout *time.Duration, combiners []Combiner[T]) *layer[T] {
+	if len(combiners) > 0 && !slices.ContainsFunc(combiners, func(c Combiner[T]) bool { return !isOptional(c) }) {
+		panic(fmt.Sprintf("layer #%d has no required combiners (every combiner is optional)", len(exp.expectLayers)))
+	}
+
 	layer := &layer[T]{
 		mode:      mode,
 		layerIdx:  len(exp.expectLayers),
 		combiners: combiners,
 		timeout:   timeout,
+		chosenIdx: -1,
+		captures:  exp.captures,
+		format:    exp.formatMessage,
+	}
+
+	for idx, combiner := range combiners {
+		if !claimCombiner(combiner, exp, false) {
+			// The layer is never added, and so the combiners it claimed are free to be used elsewhere
+			for _, claimed := range combiners[:idx] {
+				releaseCombiner(claimed, exp)
+			}
+
+			panic(fmt.Sprintf("%s of layer #%d is already in use by another layer (combiners can only be reused once the expecter using them has finished)", label("combiner", idx, combiner), layer.layerIdx))
+		}
 	}
 
 	exp.expectLayers = append(exp.expectLayers, layer)
-	return exp
+	return layer
 }
 
 // ExpectAny adds a layer to this expecter with some number of combiners. The layer
@@ -153,7 +273,8 @@ func (exp *expecter[T]) addLayer(mode LayerMode, timeout *time.Duration, combine
 // becomes satisfied (versus with [Expect] or [ExpectTimeout], where ALL combiners must
 // become satisfied).
 func (exp *expecter[T]) ExpectAny(combiners ...Combiner[T]) Expecter[T] {
-	return exp.addLayer(or, nil, combiners)
+	exp.addLayer(ModeOr, nil, combiners)
+	return exp
 }
 
 // ExpectAnyTimeout adds a layer to this expecter with some number of combiners. The layer
@@ -164,7 +285,8 @@ func (exp *expecter[T]) ExpectAny(combiners ...Combiner[T]) Expecter[T] {
 // This layer will be created with a timeout. Once the layer is selected, the timeout will
 // begin and messages delivered to the layer will be rejected once the timeout has elapsed.
 func (exp *expecter[T]) ExpectAnyTimeout(timeout time.Duration, combiners ...Combiner[T]) Expecter[T] {
-	return exp.addLayer(or, &timeout, combiners)
+	exp.addLayer(ModeOr, &timeout, combiners)
+	return exp
 }
 
 // Expect adds a layer to this expecter with some number of combiners. The layer
@@ -172,7 +294,8 @@ func (exp *expecter[T]) ExpectAnyTimeout(timeout time.Duration, combiners ...Com
 // becomes satisfied (versus with [ExpectAny] or [ExpectAnyTimeout], where only ONE of the
 // combiners must become satisfied).
 func (exp *expecter[T]) Expect(combiners ...Combiner[T]) Expecter[T] {
-	return exp.addLayer(and, nil, combiners)
+	exp.addLayer(ModeAnd, nil, combiners)
+	return exp
 }
 
 // ExpectTimeout adds a layer to this expecter with some number of combiners. The layer
@@ -183,7 +306,74 @@ func (exp *expecter[T]) Expect(combiners ...Combiner[T]) Expecter[T] {
 // This layer will be created with a timeout. Once the layer is selected, the timeout will
 // begin and messages delivered to the layer will be rejected once the timeout has elapsed.
 func (exp *expecter[T]) ExpectTimeout(timeout time.Duration, combiners ...Combiner[T]) Expecter[T] {
-	return exp.addLayer(and, &timeout, combiners)
+	exp.addLayer(ModeAnd, &timeout, combiners)
+	return exp
+}
+
+// ExpectQuorum adds a layer to this expecter with some number of combiners. The layer
+// will be in 'QUORUM' mode, which means it will become satisfied once AT LEAST K of the
+// combiners have become satisfied.
+//
+// This method will panic if k is not between 1 and the number of (non-optional) combiners provided.
+func (exp *expecter[T]) ExpectQuorum(k int, combiners ...Combiner[T]) Expecter[T] {
+	required := 0
+	for _, combiner := range combiners {
+		if !isOptional(combiner) {
+			required++
+		}
+	}
+
+	if k < 1 || k > required {
+		panic(fmt.Sprintf("quorum of %d is not possible for a layer with %d (non-optional) combiners", k, required))
+	}
+
+	exp.addLayer(ModeQuorum, nil, combiners).quorum = k
+	return exp
+}
+
+// ExpectExclusive adds a layer to this expecter with some number of combiners. The layer
+// will be in 'EXCLUSIVE' mode, which means that the first combiner to accept a message
+// will be chosen, and all further messages will ONLY be delivered to that combiner. The layer
+// becomes satisfied once the chosen combiner becomes satisfied.
+//
+// This is useful for asserting that a producer takes exactly one of several paths, as a
+// message for any other combiner will be rejected once a combiner has been chosen.
+func (exp *expecter[T]) ExpectExclusive(combiners ...Combiner[T]) Expecter[T] {
+	exp.addLayer(ModeExclusive, nil, combiners)
+	return exp
+}
+
+// ExpectLayer adds the layer provided to this expecter. This allows custom implementations
+// of [Layer] to be used alongside those created by [Expect], [ExpectAny], etc.
+//
+// Helpers such as [NewLayerStatusTrace] and [NewCombinersStatusTrace] can be used by
+// custom layers to produce traces consistent with the built-in layers.
+func (exp *expecter[T]) ExpectLayer(layer Layer[T]) Expecter[T] {
+	exp.expectLayers = append(exp.expectLayers, layer)
+	return exp
+}
+
+// Named names the layer most recently added to this expecter, so that the layer is referred
+// to by name (e.g. "layer 'handshake'") rather than by its index in traces and errors:
+//
+//	expecter.Expect(AllOf(MatchEqual("hello"))).Named("handshake")
+//
+// Custom layers (see [ExpectLayer]) cannot be named using this method, and should
+// instead implement [Namer]. This method will panic if no layers have been added, or
+// if the most recent layer is a custom layer.
+func (exp *expecter[T]) Named(name string) Expecter[T] {
+	if len(exp.expectLayers) == 0 {
+		panic("no layers specified to name")
+	}
+
+	idx := len(exp.expectLayers) - 1
+	layer, ok := exp.expectLayers[idx].(*layer[T])
+	if !ok {
+		panic(fmt.Sprintf("layer #%d is a custom layer, and cannot be named (implement Namer instead)", idx))
+	}
+
+	layer.name = name
+	return exp
 }
 
 // Listen starts the expecter by launching a goroutinue
@@ -210,9 +400,15 @@ func (exp *expecter[T]) Listen() {
 		panic("no layers specified")
 	}
 
+	exp.claimCombiners()
+	exp.startedAt = time.Now()
+	exp.layerSpans = make([]layerSpan, len(exp.expectLayers))
+
+	exp.listening.Store(true)
 	exp.wg.Add(1)
 	go func() {
 		defer exp.wg.Done()
+		defer exp.listening.Store(false)
 		exp.currentLayerIndex = 0
 		for {
 			if exp.currentLayerIndex >= len(exp.expectLayers) {
@@ -221,6 +417,7 @@ func (exp *expecter[T]) Listen() {
 
 			layer := exp.expectLayers[exp.currentLayerIndex]
 			layer.Begin()
+			exp.layerSpans[exp.currentLayerIndex].begin()
 
 			select {
 			case <-exp.closeChan:
@@ -233,12 +430,16 @@ func (exp *expecter[T]) Listen() {
 					return
 				}
 
+				receivedAt := time.Now()
 				if ok, trace := exp.shouldIgnoreMessage(message); ok {
 					exp.results = append(exp.results, MessageResult[T]{
-						Message:  message,
-						LayerIdx: -1,
-						Status:   Ignored,
-						Trace:    trace,
+						Message:     message,
+						Formatted:   exp.formatMessage(message),
+						LayerIdx:    -1,
+						Status:      Ignored,
+						Trace:       trace,
+						ReceivedAt:  receivedAt,
+						isFormatted: true,
 					})
 
 					continue
@@ -250,14 +451,26 @@ func (exp *expecter[T]) Listen() {
 					status = Accepted
 				}
 
-				exp.results = append(exp.results, MessageResult[T]{
-					Message:  message,
-					LayerIdx: exp.currentLayerIndex,
-					Status:   status,
-					Trace:    trace,
-				})
+				result := MessageResult[T]{
+					Message:     message,
+					Formatted:   exp.formatMessage(message),
+					LayerIdx:    exp.currentLayerIndex,
+					LayerName:   nameOf(layer),
+					Status:      status,
+					Trace:       trace,
+					ReceivedAt:  receivedAt,
+					isFormatted: true,
+				}
+				result.Captures, result.formattedCaptures = exp.captures.flush()
+
+				if status == Rejected {
+					result.nearMiss = exp.findNearMiss(message, exp.currentLayerIndex)
+				}
+
+				exp.results = append(exp.results, result)
 
 				if status == Accepted && layer.IsSatisfied() {
+					exp.layerSpans[exp.currentLayerIndex].finish()
 					exp.currentLayerIndex++
 					continue
 				}
@@ -285,6 +498,7 @@ func (exp *expecter[T]) awaitFinished(timeout time.Duration) bool {
 	case <-finished:
 	}
 
+	exp.finishedAt = time.Now()
 	return !terminated
 }
 
@@ -296,6 +510,7 @@ func (exp *expecter[T]) awaitFinished(timeout time.Duration) bool {
 // state of the expecter. The errors will consist of the following errors:
 //   - [TerminatedError]
 //   - [RejectionError]
+//   - [LayerError]
 //   - [UnsatisfiedError]
 func (exp *expecter[T]) AwaitSatisfied(timeout time.Duration) Errors {
 	outErr := make([]error, 0)
@@ -309,20 +524,70 @@ func (exp *expecter[T]) AwaitSatisfied(timeout time.Duration) Errors {
 
 	for idx, res := range exp.results {
 		if res.Status == Rejected {
-			reportErr(RejectionError[T]{MessageNum: idx, MessageResult: res})
+			reportErr(RejectionError[T]{MessageNum: idx, MessageResult: res, NearMiss: res.nearMiss})
+		}
+	}
+
+	exp.finishLayers()
+	for idx := range exp.expectLayers {
+		if err := exp.finishErrs[idx]; err != nil {
+			reportErr(LayerError{LayerIdx: idx, LayerName: nameOf(exp.expectLayers[idx]), Err: err})
 		}
 	}
 
 	if exp.currentLayerIndex < len(exp.expectLayers) {
 		currentLayer := exp.expectLayers[exp.currentLayerIndex]
 		if currentLayer != nil && !currentLayer.IsSatisfied() {
-			reportErr(UnsatisfiedError{exp.currentLayerIndex})
+			reportErr(UnsatisfiedError{
+				ActiveLayerIdx:  exp.currentLayerIndex,
+				ActiveLayerName: nameOf(currentLayer),
+				Report:          exp.reportLayer(exp.currentLayerIndex),
+			})
 		}
 	}
 
+	exp.errs = outErr
 	return outErr
 }
 
+// finishLayers calls Finish on each layer which was selected (see [Layer.Begin]) and has not
+// yet been finished, recording the error it returns. Layers without a Finish method always pass.
+func (exp *expecter[T]) finishLayers() {
+	if exp.finishErrs == nil {
+		exp.finishErrs = make(map[int]error)
+	}
+
+	for idx, span := range exp.layerSpans {
+		if _, finished := exp.finishErrs[idx]; span.started != nil && !finished {
+			var err error
+			if layer, ok := exp.expectLayers[idx].(finishingLayer); ok {
+				err = layer.Finish()
+			}
+
+			exp.finishErrs[idx] = err
+		}
+	}
+}
+
+// claimCombiners claims the combiners of each layer again before the expecter starts listening,
+// as another expecter may have claimed them since they were added (see [ownership]).
+func (exp *expecter[T]) claimCombiners() {
+	for layerIdx, l := range exp.expectLayers {
+		if layer, ok := l.(*layer[T]); ok {
+			for idx, combiner := range layer.combiners {
+				if !claimCombiner(combiner, exp, true) {
+					panic(fmt.Sprintf("%s of layer #%d is in use by another expecter which is listening", label("combiner", idx, combiner), layerIdx))
+				}
+			}
+		}
+	}
+}
+
+// isListening returns true while the expecter is listening to its channel.
+func (exp *expecter[T]) isListening() bool {
+	return exp.listening.Load()
+}
+
 // TestingT is a minimal interface which mimics the standard
 // [testing.T] struct. This is used in places that chanassert accepts
 // a testing.T in order to allow unit testing of it's behaviour.
@@ -339,10 +604,14 @@ type TestingT interface {
 //
 // If the expecters Debug mode is enabled, then any errors will cause the full expecter message
 // trace to be printed to the [TestingT] provided. If not Debug, then only the
-// trace for any message rejections will be printed.
+// trace for any message rejections will be printed. The trace is rendered using the
+// [IndentRenderer] unless another renderer is provided (see [WithRenderer]), and any
+// filters provided (e.g. [OnlyStatus]) select the messages included in the full trace. An
+// HTML report of the expecter can also be written on failure, see [WithHTMLReport].
 //
 // For more information on the errors the expecter can generate, see [AwaitSatisfied].
-func (exp *expecter[T]) AssertSatisfied(t TestingT, timeout time.Duration) {
+func (exp *expecter[T]) AssertSatisfied(t TestingT, timeout time.Duration, opts ...TraceOption) {
+	config := newTraceConfig(opts)
 	layers := make(map[int]struct{})
 	rejections := 0
 	errs := exp.AwaitSatisfied(timeout)
@@ -363,24 +632,59 @@ func (exp *expecter[T]) AssertSatisfied(t TestingT, timeout time.Duration) {
 		stringBuilder.WriteString("expecter error: ")
 		stringBuilder.WriteString(e.Error())
 
-		if !exp.debug {
-			var rejectErr RejectionError[T]
-			if errors.As(e, &rejectErr) {
+		var rejectErr RejectionError[T]
+		if errors.As(e, &rejectErr) {
+			// The trace of the message ends with a blank line,
+			// which separates it from the near miss (if any)
+			if !exp.debug {
+				stringBuilder.WriteString("\n")
+				config.renderer.RenderTrace(stringBuilder, rejectErr.MessageResult.entry(rejectErr.MessageNum, exp.traceLevel(config)))
+			} else if rejectErr.NearMiss != nil {
+				stringBuilder.WriteString("\n")
+			}
+
+			if rejectErr.NearMiss != nil {
+				stringBuilder.WriteString("Near miss: ")
+				stringBuilder.WriteString(rejectErr.NearMiss.String())
+			}
+		}
+
+		// Report what the unsatisfied layer was still expecting, as the
+		// trace only includes the messages it actually received
+		var unsatisfiedErr UnsatisfiedError
+		if errors.As(e, &unsatisfiedErr) {
+			if unsatisfiedErr.Report != nil {
+				stringBuilder.WriteString("\n")
+				unsatisfiedErr.Report.trace().PrintTrace(stringBuilder, false, 0)
+			} else if unsatisfiedErr.ActiveLayerIdx < len(exp.expectLayers) {
 				stringBuilder.WriteString("\n")
-				rejectErr.MessageResult.PrettyPrint(stringBuilder, false)
+				exp.describeLayer(unsatisfiedErr.ActiveLayerIdx).PrintTrace(stringBuilder, false, 0)
 			}
 		}
 
 		t.Error(stringBuilder.String())
 	}
 
+	if len(errs) > 0 && config.htmlDir != "" {
+		name := "chanassert"
+		if named, ok := t.(interface{ Name() string }); ok {
+			name = named.Name()
+		}
+
+		if path, err := writeHTMLReport(config.htmlDir, name, exp.Export()); err != nil {
+			t.Errorf("expecter error: failed to write HTML report: %s", err)
+		} else {
+			t.Logf("expecter HTML report written to %s", path)
+		}
+	}
+
 	if len(errs) > 0 {
 		if exp.debug {
 			t.Errorf("expecter error: failed to become satisfied")
 
 			stringBuilder := &strings.Builder{}
 			stringBuilder.WriteString("EXPECTER: DEBUG enabled: trace of processed messages follow:\n")
-			exp.FPrintTrace(stringBuilder)
+			exp.FPrintTrace(stringBuilder, opts...)
 			t.Log(stringBuilder.String())
 		} else {
 			t.Errorf("expecter error: failed to become satisfied: HINT: use .Debug() to enable verbose message tracing")
@@ -393,23 +697,93 @@ func (exp *expecter[T]) AssertSatisfied(t TestingT, timeout time.Duration) {
 // has no implications for other behaviour.
 //
 // If Debug is not called, then only traces for rejected
-// messages are printed when using [AssertSatisfied].
+// messages are printed when using [AssertSatisfied]. Unless a
+// verbosity is set (see [Expecter.Verbosity]), the trace of an
+// expecter in Debug mode is printed at [LevelTrace].
 func (exp *expecter[T]) Debug() Expecter[T] {
 	exp.debug = true
 	return exp
 }
 
+// FormatMessages sets the formatter used to format messages in the trace and errors of this
+// expecter (e.g. a [RejectionError]), in place of [MessageFormatter]. Use [MessageFormatter] with
+// options to truncate long messages, or redact fields which should not appear in the output; the
+// fields it redacts are also redacted from the descriptions and explanations of the built-in
+// matchers. Any function can be used as a formatter via [FormatterFunc].
+//
+// Panics if the formatter is nil.
+func (exp *expecter[T]) FormatMessages(formatter Formatter[T]) Expecter[T] {
+	if isNilFormatter(formatter) {
+		panic("FormatMessages requires a non-nil formatter")
+	}
+
+	exp.format = formatter
+	exp.captures.values = valuesOf(formatter)
+	return exp
+}
+
+func (exp *expecter[T]) formatMessage(message T) string {
+	return exp.format.Format(message)
+}
+
+// Verbosity sets the verbosity of the trace printed by this expecter, overriding
+// the verbosity implied by Debug mode. Only trace messages at or below the level
+// provided are printed. This has no implications for other behaviour.
+func (exp *expecter[T]) Verbosity(level TraceLevel) Expecter[T] {
+	exp.verbosity = &level
+	return exp
+}
+
+// Normalize enables normalisation of the trace printed by this expecter
+// (see [NormalizeTrace]), so that the trace is identical from run to run.
+// This has no implications for other behaviour.
+func (exp *expecter[T]) Normalize() Expecter[T] {
+	exp.normalize = true
+	return exp
+}
+
 // PrintTrace prints a formatted representation
 // of the expecter trace to stdout.
-func (exp *expecter[T]) PrintTrace() {
-	exp.FPrintTrace(os.Stdout)
+func (exp *expecter[T]) PrintTrace(opts ...TraceOption) {
+	exp.FPrintTrace(os.Stdout, opts...)
+}
+
+// FPrintTrace prints a formatted representation of the expecter trace to the writer
+// provided. By default, the trace is rendered using the [IndentRenderer]; see [WithRenderer].
+func (exp *expecter[T]) FPrintTrace(w io.Writer, opts ...TraceOption) {
+	config := newTraceConfig(opts)
+	if exp.normalize {
+		builder := &strings.Builder{}
+		exp.renderTrace(builder, config)
+
+		io.WriteString(w, NormalizeTrace(builder.String()))
+		return
+	}
+
+	exp.renderTrace(w, config)
+}
+
+// renderTrace renders the trace of each message which passes the filters of the config.
+func (exp *expecter[T]) renderTrace(w io.Writer, config traceConfig) {
+	level := exp.traceLevel(config)
+	for idx, msg := range exp.results {
+		if entry := msg.entry(idx, level); config.includes(msg, entry) {
+			config.renderer.RenderTrace(w, entry)
+		}
+	}
 }
 
-// FPrintTrace prints a formatted representation
-// of the expecter trace to the writer provided.
-func (exp *expecter[T]) FPrintTrace(w io.Writer) {
-	for _, msg := range exp.results {
-		msg.PrettyPrint(w, exp.debug)
+// traceLevel returns the verbosity the trace should be printed with: the verbosity of the
+// config if provided, otherwise the verbosity of the expecter, which defaults to [LevelInfo]
+// (or [LevelTrace] in Debug mode).
+func (exp *expecter[T]) traceLevel(config traceConfig) TraceLevel {
+	switch {
+	case config.verbosity != nil:
+		return *config.verbosity
+	case exp.verbosity != nil:
+		return *exp.verbosity
+	default:
+		return debugLevel(exp.debug)
 	}
 }
 
@@ -421,6 +795,51 @@ func (exp *expecter[T]) ProcessedMessages() []MessageResult[T] {
 	return exp.results
 }
 
+// Describe returns a description of everything this expecter expects, rendered as a tree
+// of layers, combiners and matchers. Any matchers or combiners which do not implement
+// [Describer] are described using their type.
+func (exp *expecter[T]) Describe() string {
+	builder := &strings.Builder{}
+	for idx := range exp.expectLayers {
+		exp.describeLayer(idx).PrintTrace(builder, false, 0)
+	}
+
+	if len(exp.ignoreMatchers) > 0 {
+		newInfoTrace("Ignoring messages matching", describeMatcherTree(exp.ignoreMatchers, exp.captures.formatter())...).PrintTrace(builder, false, 0)
+	}
+
+	return builder.String()
+}
+
+// describeLayer returns the description tree for the layer at the index provided.
+func (exp *expecter[T]) describeLayer(idx int) TraceMessage {
+	tree := describeTree(exp.expectLayers[idx], exp.captures.formatter())
+	tree.Message = fmt.Sprintf("%s: %s", label("Layer", idx, exp.expectLayers[idx]), tree.Message)
+
+	return tree
+}
+
+// reportLayer returns the report for the layer at the index provided, or
+// nil if the layer is unable to report its state.
+func (exp *expecter[T]) reportLayer(idx int) *LayerReport {
+	reporter, ok := exp.expectLayers[idx].(layerReporter)
+	if !ok {
+		return nil
+	}
+
+	report := reporter.report(exp.captures.formatter())
+	report.Label = label("Layer", idx, exp.expectLayers[idx])
+
+	return &report
+}
+
+// Captures returns all of the values which have been bound
+// by [Capture] matchers during this expecters lifetime. If a name was bound
+// multiple times, only the most recent value is returned.
+func (exp *expecter[T]) Captures() Captures {
+	return exp.captures.snapshot()
+}
+
 // shouldIgnoreMessage checks if the given message matches
 // any specified 'ignore' matcher. The boolean represents
 // whether it should be ignored.
@@ -432,8 +851,8 @@ func (exp *expecter[T]) ProcessedMessages() []MessageResult[T] {
 // If the message was not ignored (bool false), then the trace will be empty.
 func (exp *expecter[T]) shouldIgnoreMessage(message T) (bool, TraceMessage) {
 	for idx, ignore := range exp.ignoreMatchers {
-		if ignore.DoesMatch(message) {
-			return true, newInfoTrace(fmt.Sprintf("Ignore matcher #%d ACCEPTED", idx))
+		if matchWithCaptures(ignore, message, exp.captures) {
+			return true, newInfoTrace(fmt.Sprintf("%s%s ACCEPTED", label("Ignore matcher", idx, ignore), describeSuffix(ignore, exp.captures.formatter())))
 		}
 	}
 
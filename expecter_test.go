@@ -503,6 +503,389 @@ func Test_ExpectAnyTimeout_MultipleCombiner(t *testing.T) {
 	runExpecterTests(t, makeExpecter, tests)
 }
 
+func Test_ExpectQuorum_MultipleCombiner(t *testing.T) {
+	makeExpecter := func() (chan string, chanassert.Expecter[string]) {
+		ch := make(chan string, 10)
+		exp := chanassert.NewChannelExpecter(ch).ExpectQuorum(2,
+			chanassert.OneOf(chanassert.MatchEqual("a")),
+			chanassert.OneOf(chanassert.MatchEqual("b")),
+			chanassert.OneOf(chanassert.MatchEqual("c")),
+		)
+
+		return ch, exp
+	}
+
+	tests := []expecterTest[string]{
+		{
+			Summary:  "Quorum reached (A, B)",
+			Messages: []string{"a", "b"},
+		},
+		{
+			Summary:  "Quorum reached (C, A)",
+			Messages: []string{"c", "a"},
+		},
+		{
+			Summary:        "Quorum reached with unknown messages",
+			Messages:       []string{"c", "d", "b"},
+			ExpectedErrors: []expectedError{rejectedError},
+		},
+		{
+			Summary:        "Quorum not reached",
+			Messages:       []string{"b"},
+			ExpectedErrors: []expectedError{unsatisfiedError, terminatedError},
+		},
+		{
+			Summary:        "Quorum not reached with duplicate messages",
+			Messages:       []string{"b", "b"},
+			ExpectedErrors: []expectedError{rejectedError, unsatisfiedError, terminatedError},
+		},
+	}
+
+	runExpecterTests(t, makeExpecter, tests)
+
+	t.Run("Impossible quorum panics", func(t *testing.T) {
+		for _, k := range []int{0, 4} {
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("expected ExpectQuorum(%d) to panic for layer with 3 combiners", k)
+					}
+				}()
+
+				chanassert.NewChannelExpecter(make(chan string)).ExpectQuorum(k,
+					chanassert.OneOf(chanassert.MatchEqual("a")),
+					chanassert.OneOf(chanassert.MatchEqual("b")),
+					chanassert.OneOf(chanassert.MatchEqual("c")),
+				)
+			}()
+		}
+	})
+}
+
+func Test_ExpectExclusive_MultipleCombiner(t *testing.T) {
+	makeExpecter := func() (chan string, chanassert.Expecter[string]) {
+		ch := make(chan string, 10)
+		exp := chanassert.NewChannelExpecter(ch).ExpectExclusive(
+			chanassert.AllOf(
+				chanassert.MatchEqual("a1"), chanassert.MatchEqual("a2"),
+			),
+			chanassert.AllOf(
+				chanassert.MatchEqual("b1"), chanassert.MatchEqual("b2"),
+			),
+		).Expect(chanassert.OneOf(chanassert.MatchEqual("done")))
+
+		return ch, exp
+	}
+
+	tests := []expecterTest[string]{
+		{
+			Summary:  "Path A taken",
+			Messages: []string{"a2", "a1", "done"},
+		},
+		{
+			Summary:  "Path B taken",
+			Messages: []string{"b1", "b2", "done"},
+		},
+		{
+			Summary:        "Path switched from A to B",
+			Messages:       []string{"a1", "b1", "b2", "a2", "done"},
+			ExpectedErrors: []expectedError{rejectedError},
+		},
+		{
+			Summary:        "Path switched from A to B without completing A",
+			Messages:       []string{"a1", "b1", "b2"},
+			ExpectedErrors: []expectedError{rejectedError, unsatisfiedError, terminatedError},
+		},
+		{
+			Summary:        "No path taken",
+			Messages:       []string{"done"},
+			ExpectedErrors: []expectedError{rejectedError, unsatisfiedError, terminatedError},
+		},
+	}
+
+	runExpecterTests(t, makeExpecter, tests)
+}
+
+func Test_CombinerReuse(t *testing.T) {
+	assertPanics := func(t *testing.T, summary string, build func()) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected %s to panic", summary)
+			}
+		}()
+
+		build()
+	}
+
+	// listen starts an expecter using the combiners provided, which holds its claim on
+	// them until the test finishes (as no messages are sent to its channel).
+	listen := func(t *testing.T, combiners ...chanassert.Combiner[string]) {
+		exp := chanassert.NewChannelExpecter(make(chan string)).Expect(combiners...)
+		exp.Listen()
+		t.Cleanup(func() { exp.AwaitSatisfied(time.Millisecond) })
+	}
+
+	t.Run("Reused combiner panics", func(t *testing.T) {
+		shared := chanassert.OneOf(chanassert.MatchEqual("a"))
+		listen(t, shared)
+
+		assertPanics(t, "combiner reused by another expecter", func() {
+			chanassert.NewChannelExpecter(make(chan string)).Expect(shared)
+		})
+		assertPanics(t, "combiner reused within a layer", func() {
+			other := chanassert.OneOf(chanassert.MatchEqual("b"))
+			chanassert.NewChannelExpecter(make(chan string)).Expect(other, other)
+		})
+		assertPanics(t, "combiner reused by another layer", func() {
+			other := chanassert.OneOf(chanassert.MatchEqual("b"))
+			chanassert.NewChannelExpecter(make(chan string)).Expect(other).Expect(other)
+		})
+	})
+
+	t.Run("Reused nested combiner panics", func(t *testing.T) {
+		shared := chanassert.OneOf(chanassert.MatchEqual("a"))
+		listen(t, chanassert.AnyCombiner[string](shared))
+
+		assertPanics(t, "nested combiner reused by another layer", func() {
+			chanassert.NewChannelExpecter(make(chan string)).Expect(chanassert.Optional[string](shared))
+		})
+	})
+
+	t.Run("Layer which panics releases its combiners", func(t *testing.T) {
+		shared := chanassert.OneOf(chanassert.MatchEqual("a"))
+		listen(t, shared)
+
+		fresh := chanassert.AnyCombiner[string](chanassert.OneOf(chanassert.MatchEqual("b")))
+		exp := chanassert.NewChannelExpecter(make(chan string))
+		assertPanics(t, "layer with a combiner in use", func() {
+			exp.Expect(fresh, shared)
+		})
+
+		exp.Expect(fresh)
+	})
+
+	t.Run("Combiner claimed by another expecter panics on Listen", func(t *testing.T) {
+		shared := chanassert.OneOf(chanassert.MatchEqual("a"))
+		exp := chanassert.NewChannelExpecter(make(chan string)).Expect(shared)
+		listen(t, shared)
+
+		assertPanics(t, "expecter listening with a combiner in use", exp.Listen)
+	})
+
+	t.Run("Combiner of an expecter which never listened can be reused", func(t *testing.T) {
+		shared := chanassert.OneOf(chanassert.MatchEqual("a"))
+		chanassert.NewChannelExpecter(make(chan string)).Expect(shared)
+		listen(t, shared)
+	})
+
+	t.Run("Reused combiner is reset", func(t *testing.T) {
+		shared := chanassert.AllCombiners[string](
+			chanassert.AllOf(chanassert.MatchEqual("a"), chanassert.MatchEqual("b")),
+		)
+
+		for run := 1; run <= 2; run++ {
+			ch := make(chan string, 10)
+			exp := chanassert.NewChannelExpecter(ch).Expect(shared)
+			exp.Listen()
+			ch <- "a"
+			ch <- "b"
+
+			if errs := exp.AwaitSatisfied(time.Second); len(errs) != 0 {
+				t.Errorf("expected run #%d to be satisfied, but got errors: %v", run, errs)
+			}
+		}
+	})
+}
+
+func Test_Named(t *testing.T) {
+	ch := make(chan string, 10)
+	exp := chanassert.NewChannelExpecter(ch).
+		Expect(
+			chanassert.AllOf(chanassert.NamedMatcher("greeting", chanassert.MatchEqual("hello"))).Named("auth events"),
+		).Named("handshake").
+		ExpectTimeout(time.Millisecond*100, chanassert.OneOf(chanassert.MatchEqual("bye")))
+
+	exp.Listen()
+	ch <- "foo"
+	ch <- "hello"
+	errs := exp.AwaitSatisfied(time.Millisecond * 200)
+
+	var messages []string
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+
+	for _, expected := range []string{
+		"message #0 (foo) was unexpected by layer 'handshake'",
+		"active layer (layer #1) never became satisfied",
+	} {
+		if !strings.Contains(strings.Join(messages, "\n"), expected) {
+			t.Errorf("expected errors to contain %q, but got: %v", expected, messages)
+		}
+	}
+
+	trace := &strings.Builder{}
+	exp.FPrintTrace(trace)
+	for _, expected := range []string{
+		"Layer 'handshake' could not match message against any combiners",
+		"Layer 'handshake' matched message against combiner 'auth events'",
+		"Combiner 'auth events': Combiner matched on matcher #0",
+		"Matcher 'greeting' (equals \"hello\") ACCEPT",
+	} {
+		if !strings.Contains(trace.String(), expected) {
+			t.Errorf("expected trace to contain %q, but it did not:\n%s", expected, trace)
+		}
+	}
+
+	description := exp.Describe()
+	for _, expected := range []string{
+		"Layer 'handshake': ALL combiners must be satisfied",
+		"Combiner 'auth events': exactly 1 message(s) matching each of",
+		"Matcher 'greeting': equals \"hello\"",
+		"Layer #1: ALL combiners must be satisfied",
+	} {
+		if !strings.Contains(description, expected) {
+			t.Errorf("expected description to contain %q, but it did not:\n%s", expected, description)
+		}
+	}
+
+	t.Run("Naming without layers panics", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected naming an expecter without layers to panic")
+			}
+		}()
+
+		chanassert.NewChannelExpecter(make(chan string)).Named("handshake")
+	})
+
+	t.Run("Naming a custom layer panics", func(t *testing.T) {
+		defer func() {
+			expected := "layer #1 is a custom layer, and cannot be named (implement Namer instead)"
+			if r := recover(); r != expected {
+				t.Errorf("expected naming a custom layer to panic with %q, got %v", expected, r)
+			}
+		}()
+
+		chanassert.NewChannelExpecter(make(chan string)).
+			Expect(chanassert.OneOf(chanassert.MatchEqual("hello"))).
+			ExpectLayer(&firstWinsLayer{}).
+			Named("handshake")
+	})
+}
+
+func Test_OptionalCombiners(t *testing.T) {
+	t.Run("Expect", func(t *testing.T) {
+		makeExpecter := func() (chan string, chanassert.Expecter[string]) {
+			ch := make(chan string, 10)
+			exp := chanassert.NewChannelExpecter(ch).
+				Expect(
+					chanassert.AllOf(chanassert.MatchEqual("hello"), chanassert.MatchEqual("world")),
+					chanassert.MayOccur(2, chanassert.MatchEqual("heartbeat")),
+				).
+				Expect(chanassert.OneOf(chanassert.MatchEqual("done")))
+
+			return ch, exp
+		}
+
+		tests := []expecterTest[string]{
+			{
+				Summary:  "Optional messages not delivered",
+				Messages: []string{"hello", "world", "done"},
+			},
+			{
+				Summary:  "Optional messages delivered",
+				Messages: []string{"heartbeat", "hello", "heartbeat", "world", "done"},
+			},
+			{
+				Summary:        "Too many optional messages delivered",
+				Messages:       []string{"heartbeat", "heartbeat", "heartbeat", "hello", "world", "done"},
+				ExpectedErrors: []expectedError{rejectedError},
+			},
+			{
+				Summary:        "Optional messages do not hold layer open",
+				Messages:       []string{"hello", "world", "heartbeat", "done"},
+				ExpectedErrors: []expectedError{rejectedError},
+			},
+		}
+
+		runExpecterTests(t, makeExpecter, tests)
+	})
+
+	t.Run("ExpectAny", func(t *testing.T) {
+		makeExpecter := func() (chan string, chanassert.Expecter[string]) {
+			ch := make(chan string, 10)
+			exp := chanassert.NewChannelExpecter(ch).
+				ExpectAny(
+					chanassert.OneOf(chanassert.MatchEqual("hello")),
+					chanassert.Optional[string](chanassert.AtLeastNOf(1, chanassert.MatchEqual("heartbeat"))),
+				).
+				Expect(chanassert.OneOf(chanassert.MatchEqual("done")))
+
+			return ch, exp
+		}
+
+		tests := []expecterTest[string]{
+			{
+				Summary:  "Optional messages do not cause layer to advance",
+				Messages: []string{"heartbeat", "heartbeat", "hello", "done"},
+			},
+			{
+				Summary:        "Only optional messages delivered",
+				Messages:       []string{"heartbeat", "done"},
+				ExpectedErrors: []expectedError{rejectedError, unsatisfiedError, terminatedError},
+			},
+		}
+
+		runExpecterTests(t, makeExpecter, tests)
+	})
+
+	t.Run("Trace", func(t *testing.T) {
+		ch := make(chan string, 10)
+		exp := chanassert.NewChannelExpecter(ch).Expect(
+			chanassert.OneOf(chanassert.MatchEqual("hello")),
+			chanassert.MayOccur(2, chanassert.MatchEqual("heartbeat")),
+		)
+		exp.Listen()
+		ch <- "heartbeat"
+		ch <- "hello"
+		exp.AssertSatisfied(t, time.Second)
+
+		builder := &strings.Builder{}
+		for _, res := range exp.ProcessedMessages() {
+			res.PrettyPrint(builder, true)
+		}
+
+		for _, expected := range []string{"Matcher #0 => 1 message(s)", "SATISFIED: all combiners satisfied (1) (optional combiners [#1] ignored)"} {
+			if !strings.Contains(builder.String(), expected) {
+				t.Errorf("expected trace to contain %q, but it did not:\n%s", expected, builder.String())
+			}
+		}
+	})
+
+	t.Run("Only optional combiners panics", func(t *testing.T) {
+		for summary, add := range map[string]func(chanassert.Expecter[string], ...chanassert.Combiner[string]) chanassert.Expecter[string]{
+			"Expect":          chanassert.Expecter[string].Expect,
+			"ExpectAny":       chanassert.Expecter[string].ExpectAny,
+			"ExpectExclusive": chanassert.Expecter[string].ExpectExclusive,
+		} {
+			optional := chanassert.MayOccur(1, chanassert.MatchEqual("heartbeat"))
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("expected %s with only optional combiners to panic", summary)
+					}
+				}()
+
+				add(chanassert.NewChannelExpecter(make(chan string)), optional, chanassert.Optional[string](chanassert.OneOf(chanassert.MatchEqual("hello"))))
+			}()
+
+			// The combiners must not have been claimed by the layer
+			chanassert.NewChannelExpecter(make(chan string)).Expect(chanassert.OneOf(chanassert.MatchEqual("hello")), optional)
+		}
+	})
+}
+
 // mockTestingT is a simple helper which allows
 // us to enforce that messages contains specified
 // substrings were observed as being 'delivered' to the
@@ -592,8 +975,9 @@ func expectTerminated() dataExpect {
 
 func expectLayerUnsatisfied(layerIdx int) dataExpect {
 	return dataExpect{
-		message: chanassert.UnsatisfiedError{layerIdx}.Error(),
+		message: fmt.Sprintf("%s\n  - Layer #%d: ", chanassert.UnsatisfiedError{ActiveLayerIdx: layerIdx}.Error(), layerIdx),
 		err:     true,
+		substr:  true,
 	}
 }
 
@@ -0,0 +1,221 @@
+package chanassert
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"time"
+)
+
+// ExportVersion is the version of the JSON encoding produced by [Expecter.WriteJSON]. The version
+// is incremented whenever a field is removed or changes meaning, but not when fields are added.
+const ExportVersion = 1
+
+// Export is a snapshot of an expecter, covering each of its layers, every message it processed
+// (along with the trace of each), and the errors reported by the most recent call to AwaitSatisfied
+// (or AssertSatisfied). It is intended to be encoded as JSON, see [Expecter.WriteJSON].
+type Export[T any] struct {
+	Version int           `json:"version"`
+	Layers  []ExportLayer `json:"layers"`
+	Ignore  []string      `json:"ignore,omitempty"`
+
+	// Messages holds the result of each message. Note that the message itself is included as-is,
+	// alongside the formatted message (see [Expecter.FormatMessages]), and so is not redacted
+	// unless the export was taken using [WithoutRawMessages].
+	Messages []MessageResult[T] `json:"messages"`
+	Errors   []ExportError      `json:"errors"`
+	// StartedAt is when the expecter began listening, and FinishedAt when it
+	// finished (or was terminated). Both are zero if the expecter never listened.
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// ExportLayer describes a layer, and its state, within an [Export].
+type ExportLayer struct {
+	Index       int    `json:"index"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description"`
+	Satisfied   bool   `json:"satisfied"`
+
+	// Report is the state of the layer and each of its combiners, which is
+	// nil for custom layers (as they are unable to report their state).
+	Report *LayerReport `json:"report,omitempty"`
+	// StartedAt is when the layer was selected, and FinishedAt when it became satisfied. These
+	// are nil if the layer was never selected, or never became satisfied, respectively.
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+
+	// Timeout is the timeout of the layer (see [Expecter.ExpectTimeout]), if any.
+	Timeout *time.Duration `json:"timeout,omitempty"`
+}
+
+// ExportError describes an error within an [Export]. Kind is one of "rejection", "terminated",
+// "layer" or "unsatisfied", and the remaining fields are populated depending on the kind of error.
+type ExportError struct {
+	Kind     string `json:"kind"`
+	Error    string `json:"error"`
+	LayerIdx *int   `json:"layer_idx,omitempty"`
+
+	// MessageNum and NearMiss are only populated for rejections.
+	MessageNum *int      `json:"message_num,omitempty"`
+	NearMiss   *NearMiss `json:"near_miss,omitempty"`
+}
+
+type exportConfig struct {
+	withoutRawMessages bool
+}
+
+// ExportOption configures the [Export] taken by [Expecter.Export] and [Expecter.WriteJSON].
+type ExportOption func(*exportConfig)
+
+// WithoutRawMessages omits the messages themselves, and the values captured from them (see [Capture]),
+// from the export, leaving only their formatted form (see [Expecter.FormatMessages]). The Message and
+// Captures of each result are left as their zero values, and are omitted from the JSON encoding.
+//
+// Everything else in the export (e.g. traces and near misses) is formatted by the formatter, so fields
+// it redacts do not appear in the export unless a custom matcher or combiner includes them in its trace.
+func WithoutRawMessages() ExportOption {
+	return func(config *exportConfig) {
+		config.withoutRawMessages = true
+	}
+}
+
+// Export returns a snapshot of this expecter, which should only be taken once the
+// expecter has finished (i.e. after calling AwaitSatisfied or AssertSatisfied).
+func (exp *expecter[T]) Export(opts ...ExportOption) Export[T] {
+	config := exportConfig{}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	export := Export[T]{
+		Version:    ExportVersion,
+		Layers:     make([]ExportLayer, 0, len(exp.expectLayers)),
+		Messages:   slices.Clone(exp.results),
+		Errors:     make([]ExportError, 0, len(exp.errs)),
+		StartedAt:  exp.startedAt,
+		FinishedAt: exp.finishedAt,
+	}
+
+	for idx, layer := range exp.expectLayers {
+		exportLayer := ExportLayer{
+			Index:       idx,
+			Name:        nameOf(layer),
+			Description: describeWith(layer, exp.captures.formatter()),
+			Satisfied:   layer.IsSatisfied(),
+			Report:      exp.reportLayer(idx),
+		}
+
+		if idx < len(exp.layerSpans) {
+			exportLayer.StartedAt = exp.layerSpans[idx].started
+			exportLayer.FinishedAt = exp.layerSpans[idx].finished
+		}
+
+		if timed, ok := layer.(timedLayer); ok {
+			exportLayer.Timeout = timed.layerTimeout()
+		}
+
+		export.Layers = append(export.Layers, exportLayer)
+	}
+
+	for _, m := range exp.ignoreMatchers {
+		export.Ignore = append(export.Ignore, describeWith(m, exp.captures.formatter()))
+	}
+
+	for _, err := range exp.errs {
+		export.Errors = append(export.Errors, exportError[T](err))
+	}
+
+	if config.withoutRawMessages {
+		for idx, result := range export.Messages {
+			var zero T
+			export.Messages[idx].formattedCaptures = result.captures()
+			export.Messages[idx].Message, export.Messages[idx].Captures = zero, nil
+			export.Messages[idx].omitMessage = true
+		}
+	}
+
+	return export
+}
+
+// WriteJSON writes the [Export] of this expecter to the writer provided as indented JSON,
+// which is suitable for archiving (e.g. as a CI artifact) and for processing by other tools.
+func (exp *expecter[T]) WriteJSON(w io.Writer, opts ...ExportOption) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(exp.Export(opts...))
+}
+
+// messageResultJSON has the fields of [MessageResult], without its JSON methods.
+type messageResultJSON[T any] MessageResult[T]
+
+// MarshalJSON encodes the result, omitting the formatted message if the result was not produced
+// by an expecter, and the message itself if it was omitted from the export (see [WithoutRawMessages]).
+// The captures are encoded both as-is and as formatted for the trace.
+func (result MessageResult[T]) MarshalJSON() ([]byte, error) {
+	encoded := struct {
+		messageResultJSON[T]
+		Message           *T       `json:"message,omitempty"`
+		Formatted         *string  `json:"formatted,omitempty"`
+		FormattedCaptures Captures `json:"formatted_captures,omitempty"`
+	}{messageResultJSON: messageResultJSON[T](result), FormattedCaptures: result.captures()}
+
+	if !result.omitMessage {
+		encoded.Message = &result.Message
+	}
+
+	if result.isFormatted {
+		encoded.Formatted = &result.Formatted
+	}
+
+	return json.Marshal(encoded)
+}
+
+// UnmarshalJSON decodes the result, treating it as formatted if the JSON contains the formatted message.
+func (result *MessageResult[T]) UnmarshalJSON(data []byte) error {
+	decoded := struct {
+		*messageResultJSON[T]
+		Formatted         *string  `json:"formatted"`
+		FormattedCaptures Captures `json:"formatted_captures"`
+	}{messageResultJSON: (*messageResultJSON[T])(result)}
+
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	result.formattedCaptures = decoded.FormattedCaptures
+
+	if decoded.Formatted != nil {
+		result.Formatted, result.isFormatted = *decoded.Formatted, true
+	}
+
+	return nil
+}
+
+func exportError[T any](err error) ExportError {
+	export := ExportError{Error: err.Error()}
+
+	var rejectErr RejectionError[T]
+	var layerErr LayerError
+	var unsatisfiedErr UnsatisfiedError
+	var terminatedErr TerminatedError
+	switch {
+	case errors.As(err, &rejectErr):
+		export.Kind = "rejection"
+		export.LayerIdx = &rejectErr.MessageResult.LayerIdx
+		export.MessageNum = &rejectErr.MessageNum
+		export.NearMiss = rejectErr.NearMiss
+	case errors.As(err, &layerErr):
+		export.Kind = "layer"
+		export.LayerIdx = &layerErr.LayerIdx
+	case errors.As(err, &unsatisfiedErr):
+		export.Kind = "unsatisfied"
+		export.LayerIdx = &unsatisfiedErr.ActiveLayerIdx
+	case errors.As(err, &terminatedErr):
+		export.Kind = "terminated"
+	}
+
+	return export
+}
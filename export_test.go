@@ -0,0 +1,172 @@
+package chanassert_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hbomb79/go-chanassert"
+)
+
+func Test_WriteJSON(t *testing.T) {
+	ch := make(chan string, 10)
+	exp := chanassert.NewChannelExpecter(ch).
+		Ignore(chanassert.MatchStringContains("heartbeat")).
+		Expect(chanassert.AllOf(chanassert.MatchEqual("hello"))).Named("greeting").
+		Expect(chanassert.ExactlyNOf(2, chanassert.MatchEqual("world")))
+
+	exp.Listen()
+	for _, m := range []string{"heartbeat", "world", "hello", "world"} {
+		ch <- m
+	}
+	exp.AwaitSatisfied(time.Millisecond * 100)
+
+	buffer := &bytes.Buffer{}
+	if err := exp.WriteJSON(buffer); err != nil {
+		t.Fatalf("unexpected error writing JSON: %s", err)
+	}
+
+	for _, expected := range []string{`"version": 1`, `"layer_idx": 1`, `"layer_name": "greeting"`, `"status": "REJECTED"`, `"mode": "info"`, `"near_miss": {`} {
+		if !strings.Contains(buffer.String(), expected) {
+			t.Errorf("expected JSON to contain %s, but it did not:\n%s", expected, buffer)
+		}
+	}
+
+	var export chanassert.Export[string]
+	if err := json.Unmarshal(buffer.Bytes(), &export); err != nil {
+		t.Fatalf("unexpected error decoding JSON: %s", err)
+	}
+
+	if export.Version != chanassert.ExportVersion {
+		t.Errorf("expected version %d, got %d", chanassert.ExportVersion, export.Version)
+	}
+
+	if len(export.Layers) != 2 || export.Layers[0].Name != "greeting" || !export.Layers[0].Satisfied || export.Layers[1].Satisfied {
+		t.Errorf("unexpected layers: %+v", export.Layers)
+	}
+
+	if report := export.Layers[1].Report; report == nil || report.Combiners[0].Matchers[0].Count != 1 || report.Mode != chanassert.ModeAnd {
+		t.Errorf("unexpected report for layer #1: %+v", report)
+	}
+
+	if len(export.Ignore) != 1 || export.Ignore[0] != `contains "heartbeat"` {
+		t.Errorf("unexpected ignore matchers: %v", export.Ignore)
+	}
+
+	statuses := make([]chanassert.MessageStatus, 0, len(export.Messages))
+	for _, m := range export.Messages {
+		statuses = append(statuses, m.Status)
+	}
+	if expected := []chanassert.MessageStatus{chanassert.Ignored, chanassert.Rejected, chanassert.Accepted, chanassert.Accepted}; !slices.Equal(statuses, expected) {
+		t.Errorf("expected message statuses %v, got %v", expected, statuses)
+	}
+
+	if trace := export.Messages[1].Trace; trace.Message != exp.ProcessedMessages()[1].Trace.Message || len(trace.Nested) == 0 {
+		t.Errorf("expected trace of message #1 to survive encoding, got %+v", trace)
+	}
+
+	kinds := make([]string, 0, len(export.Errors))
+	for _, e := range export.Errors {
+		kinds = append(kinds, e.Kind)
+	}
+	if strings.Join(kinds, ",") != "terminated,rejection,unsatisfied" {
+		t.Errorf("expected errors [terminated rejection unsatisfied], got %v", kinds)
+	}
+
+	if rejection := export.Errors[1]; *rejection.MessageNum != 1 || *rejection.LayerIdx != 0 || rejection.NearMiss == nil || rejection.NearMiss.LayerIdx != 1 {
+		t.Errorf("unexpected rejection error: %+v", rejection)
+	}
+}
+
+func Test_Export_CopiesMessages(t *testing.T) {
+	exp := makeReportedExpecter()
+	export := exp.Export()
+	export.Messages[0].Status = chanassert.Accepted
+	export.Messages = append(export.Messages[:1], export.Messages[2:]...)
+
+	processed := exp.ProcessedMessages()
+	if len(processed) != 3 || processed[0].Status != chanassert.Rejected || processed[1].Formatted != "hello" {
+		t.Errorf("expected modifying the export not to modify the expecter, got %+v", processed)
+	}
+}
+
+func Test_WriteJSON_WithoutRawMessages(t *testing.T) {
+	type account struct {
+		Name  string
+		Token string
+	}
+
+	ch := make(chan account, 10)
+	exp := chanassert.NewChannelExpecter(ch).
+		Expect(chanassert.OneOf(
+			chanassert.Capture("token", func(a account) any { return a.Token }, chanassert.MatchStructFields[account](map[string]any{"Name": "a"})),
+		)).
+		FormatMessages(chanassert.MessageFormatter[account](chanassert.RedactFields("Token")))
+
+	exp.Listen()
+	ch <- account{Name: "a", Token: "secret"}
+	exp.AwaitSatisfied(time.Millisecond * 100)
+
+	buffer := &bytes.Buffer{}
+	if err := exp.WriteJSON(buffer, chanassert.WithoutRawMessages()); err != nil {
+		t.Fatalf("unexpected error writing JSON: %s", err)
+	}
+
+	if strings.Contains(buffer.String(), "secret") {
+		t.Errorf("expected JSON to omit the raw messages, got:\n%s", buffer)
+	}
+
+	var export chanassert.Export[account]
+	if err := json.Unmarshal(buffer.Bytes(), &export); err != nil {
+		t.Fatalf("unexpected error decoding JSON: %s", err)
+	}
+
+	if len(export.Messages) != 1 || export.Messages[0].Message != (account{}) || export.Messages[0].Formatted != "{Name:a Token:<redacted>}" {
+		t.Errorf("expected only the formatted message to be exported, got %+v", export.Messages)
+	}
+
+	if len(export.Messages) > 0 && export.Messages[0].Captures != nil {
+		t.Errorf("expected the raw captures to be omitted from the export, got %v", export.Messages[0].Captures)
+	}
+
+	// The decoded export is rendered using the formatted messages
+	html := &bytes.Buffer{}
+	if err := chanassert.WriteHTML(html, "redacted", export); err != nil {
+		t.Fatalf("unexpected error writing HTML report: %s", err)
+	}
+
+	for _, expected := range []string{"{Name:a Token:&lt;redacted&gt;}", "token=&lt;redacted&gt;"} {
+		if !strings.Contains(html.String(), expected) {
+			t.Errorf("expected HTML report to contain %q, got:\n%s", expected, html)
+		}
+	}
+
+	if exp.ProcessedMessages()[0].Message.Token != "secret" {
+		t.Errorf("expected the export not to modify the messages of the expecter")
+	}
+}
+
+func Test_FormatMessages_EmptyString(t *testing.T) {
+	ch := make(chan string, 10)
+	exp := chanassert.NewChannelExpecter(ch).
+		Expect(chanassert.OneOf(chanassert.MatchEqual("hello"))).
+		FormatMessages(chanassert.FormatterFunc[string](func(string) string { return "" }))
+
+	exp.Listen()
+	ch <- "secret"
+	exp.AwaitSatisfied(time.Millisecond * 100)
+
+	buffer := &bytes.Buffer{}
+	exp.FPrintTrace(buffer)
+	if err := exp.WriteJSON(buffer); err != nil {
+		t.Fatalf("unexpected error writing JSON: %s", err)
+	}
+
+	output := strings.ReplaceAll(buffer.String(), `"message": "secret"`, "")
+	if strings.Contains(output, "secret") || !strings.Contains(output, `"formatted": ""`) {
+		t.Errorf("expected messages formatted as an empty string not to be reformatted, got:\n%s", buffer)
+	}
+}
@@ -0,0 +1,376 @@
+package chanassert
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// redactTag is the key of the struct tag which marks a field as redacted (`chanassert:"redact"`),
+// regardless of the formatter used.
+const redactTag = "chanassert"
+
+// redacted replaces the value of redacted fields.
+const redacted = "<redacted>"
+
+// valueFormatter formats messages, and the values within them, for the trace and errors of an
+// expecter. It is shared by the formatter of the expecter (see [MessageFormatter]) and the matchers,
+// so that redacted fields are redacted wherever they appear, including in matcher descriptions and diffs.
+type valueFormatter struct {
+	maxLength int
+	fields    []string
+	tags      []structTag
+
+	// messages is the formatter provided to [Expecter.FormatMessages], if it was not
+	// created by [MessageFormatter]. Matchers use it to format whole messages.
+	messages any
+}
+
+// structTag is a key and value of a struct tag, which redacts the fields tagged with them (see [RedactTag]).
+type structTag struct {
+	key   string
+	value string
+}
+
+// defaultValueFormatter only redacts the fields tagged `chanassert:"redact"`.
+var defaultValueFormatter = newValueFormatter()
+
+func newValueFormatter(opts ...FormatOption) *valueFormatter {
+	formatter := &valueFormatter{}
+	for _, opt := range opts {
+		opt(formatter)
+	}
+
+	return formatter
+}
+
+// FormatOption configures the formatter returned by [MessageFormatter].
+type FormatOption func(*valueFormatter)
+
+// MaxLength truncates formatted messages to at most n characters (followed by "...").
+func MaxLength(n int) FormatOption {
+	return func(formatter *valueFormatter) {
+		formatter.maxLength = n
+	}
+}
+
+// RedactFields replaces the value of the struct fields with the names provided with "<redacted>".
+func RedactFields(names ...string) FormatOption {
+	return func(formatter *valueFormatter) {
+		formatter.fields = append(formatter.fields, names...)
+	}
+}
+
+// RedactTag replaces the value of the struct fields whose tag has the key and value
+// provided (e.g. RedactTag("log", "secret") redacts fields tagged `log:"secret"`) with "<redacted>". It
+// can be provided more than once, including with the same key, to redact fields with any of the tags.
+func RedactTag(key string, value string) FormatOption {
+	return func(formatter *valueFormatter) {
+		formatter.tags = append(formatter.tags, structTag{key: key, value: value})
+	}
+}
+
+// Formatter formats messages for the trace and errors of an expecter (see [Expecter.FormatMessages]).
+type Formatter[T any] interface {
+	Format(message T) string
+}
+
+// FormatterFunc adapts a function to a [Formatter], so that any func(T) string can be used
+// to format messages. Fields redacted by such a function are not known to the matchers, and so
+// only the fields tagged `chanassert:"redact"` are redacted from matcher descriptions and diffs.
+type FormatterFunc[T any] func(message T) string
+
+func (format FormatterFunc[T]) Format(message T) string {
+	return format(message)
+}
+
+type messageFormatter[T any] struct {
+	values *valueFormatter
+}
+
+// MessageFormatter returns a formatter which formats messages in the same way as the %+v verb, other
+// than for the options provided (see [MaxLength], [RedactFields] and [RedactTag]). Fields tagged
+// `chanassert:"redact"` are always redacted. This is the formatter used by expecters unless another
+// is provided using [Expecter.FormatMessages]. When used by an expecter, the fields it redacts are also
+// redacted from the descriptions and diffs of the built-in matchers.
+func MessageFormatter[T any](opts ...FormatOption) *messageFormatter[T] {
+	return &messageFormatter[T]{values: newValueFormatter(opts...)}
+}
+
+func (formatter *messageFormatter[T]) Format(message T) string {
+	return formatter.values.formatMessage(reflect.ValueOf(message))
+}
+
+// valuesOf returns the value formatter which should be used alongside the formatter provided.
+func valuesOf[T any](formatter Formatter[T]) *valueFormatter {
+	if messages, ok := formatter.(*messageFormatter[T]); ok {
+		return messages.values
+	}
+
+	values := newValueFormatter()
+	values.messages = formatter
+
+	return values
+}
+
+// isNilFormatter returns true if the formatter is nil, or is a nil function or pointer.
+func isNilFormatter[T any](formatter Formatter[T]) bool {
+	if formatter == nil {
+		return true
+	}
+
+	rv := reflect.ValueOf(formatter)
+	//exhaustive:ignore
+	switch rv.Kind() {
+	case reflect.Func, reflect.Pointer, reflect.Map, reflect.Interface, reflect.Slice, reflect.Chan:
+		return rv.IsNil()
+	}
+
+	return false
+}
+
+// formatMessage formats a whole message.
+func (formatter *valueFormatter) formatMessage(v reflect.Value) string {
+	return formatter.truncate(formatter.format(v, 0))
+}
+
+// formatValue formats a value for use in a matcher description or explanation, quoting strings.
+// Values without redacted fields are formatted using the %v verb. Values are never truncated.
+func (formatter *valueFormatter) formatValue(v reflect.Value) string {
+	for v.IsValid() && v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	switch {
+	case !v.IsValid():
+		return "<nil>"
+	case v.Kind() == reflect.String:
+		return fmt.Sprintf("%q", v.String())
+	case formatter.hasRedacted(v.Type(), make(map[reflect.Type]bool)):
+		return formatter.format(v, 0)
+	}
+
+	return fmt.Sprintf("%v", v)
+}
+
+// formatTarget formats a message used as the target of a matcher (e.g. [MatchEqual]), using
+// the formatter provided to the expecter if it was not created by [MessageFormatter], and
+// otherwise the format function provided.
+func formatTarget[T any](formatter *valueFormatter, target T, format func(reflect.Value) string) string {
+	if messages, ok := formatter.messages.(Formatter[T]); ok {
+		return messages.Format(target)
+	}
+
+	return format(reflect.ValueOf(target))
+}
+
+// formatCaptured formats a value captured from the message provided (see [Capture]). As captured values are
+// typically extracted from the fields of the message, the value is redacted if it is the value of a redacted field.
+func (formatter *valueFormatter) formatCaptured(message reflect.Value, value any) string {
+	if formatter.holdsRedacted(message, value, make(map[uintptr]bool)) {
+		return redacted
+	}
+
+	return formatter.format(reflect.ValueOf(value), 0)
+}
+
+// holdsRedacted returns true if v contains a redacted field whose value is deeply equal to the value provided.
+func (formatter *valueFormatter) holdsRedacted(v reflect.Value, value any, seen map[uintptr]bool) bool {
+	if !v.IsValid() || !formatter.hasRedacted(v.Type(), make(map[reflect.Type]bool)) {
+		return false
+	}
+
+	//exhaustive:ignore
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() || seen[v.Pointer()] {
+			return false
+		}
+		seen[v.Pointer()] = true
+
+		return formatter.holdsRedacted(v.Elem(), value, seen)
+	case reflect.Interface:
+		return formatter.holdsRedacted(v.Elem(), value, seen)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			if formatter.isRedacted(v.Type().Field(i)) {
+				if field.CanInterface() && reflect.DeepEqual(field.Interface(), value) {
+					return true
+				}
+
+				continue
+			}
+
+			if formatter.holdsRedacted(field, value, seen) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if formatter.holdsRedacted(v.Index(i), value, seen) {
+				return true
+			}
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			if formatter.holdsRedacted(v.MapIndex(key), value, seen) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// formatWhole formats a whole message in the same way as the %+v verb, without truncating it.
+func (formatter *valueFormatter) formatWhole(v reflect.Value) string {
+	return formatter.format(v, 0)
+}
+
+func (formatter *valueFormatter) truncate(str string) string {
+	if formatter.maxLength <= 0 || utf8.RuneCountInString(str) <= formatter.maxLength {
+		return str
+	}
+
+	return string([]rune(str)[:formatter.maxLength]) + "..."
+}
+
+func (formatter *valueFormatter) isRedacted(field reflect.StructField) bool {
+	if field.Tag.Get(redactTag) == "redact" || slices.Contains(formatter.fields, field.Name) {
+		return true
+	}
+
+	for _, tag := range formatter.tags {
+		if field.Tag.Get(tag.key) == tag.value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasRedacted returns true if values of the type provided can contain redacted fields.
+func (formatter *valueFormatter) hasRedacted(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+
+	//exhaustive:ignore
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if formatter.isRedacted(t.Field(i)) || formatter.hasRedacted(t.Field(i).Type, seen) {
+				return true
+			}
+		}
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return formatter.hasRedacted(t.Elem(), seen)
+	case reflect.Interface:
+		// The dynamic type of the value is not known
+		return true
+	}
+
+	return false
+}
+
+// format formats the value using the %+v verb, unless it contains redacted fields, in which case
+// it is formatted piece-by-piece in the same way as %+v would. Like %+v, values which implement
+// error or [fmt.Stringer] are formatted using those methods, and only pointers at the top level
+// are followed (nested pointers are formatted as addresses).
+func (formatter *valueFormatter) format(v reflect.Value, depth int) string {
+	if !v.IsValid() {
+		return "<nil>"
+	}
+
+	if v.CanInterface() {
+		//exhaustive:ignore
+		switch v.Interface().(type) {
+		case error, fmt.Stringer:
+			return fmt.Sprintf("%+v", v.Interface())
+		}
+	}
+
+	if depth > 0 && v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "<nil>"
+		}
+
+		return fmt.Sprintf("0x%x", v.Pointer())
+	}
+
+	if !formatter.hasRedacted(v.Type(), make(map[reflect.Type]bool)) {
+		if v.CanInterface() {
+			return fmt.Sprintf("%+v", v.Interface())
+		}
+
+		return fmt.Sprintf("%+v", v)
+	}
+
+	//exhaustive:ignore
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return "<nil>"
+		}
+
+		return "&" + formatter.format(v.Elem(), depth+1)
+	case reflect.Interface:
+		return formatter.format(v.Elem(), depth)
+	case reflect.Struct:
+		fields := make([]string, 0, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if formatter.isRedacted(field) {
+				fields = append(fields, fmt.Sprintf("%s:%s", field.Name, redacted))
+			} else {
+				fields = append(fields, fmt.Sprintf("%s:%s", field.Name, formatter.format(v.Field(i), depth+1)))
+			}
+		}
+
+		return "{" + strings.Join(fields, " ") + "}"
+	case reflect.Slice, reflect.Array:
+		elements := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			elements = append(elements, formatter.format(v.Index(i), depth+1))
+		}
+
+		return "[" + strings.Join(elements, " ") + "]"
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return compareKeys(keys[i], keys[j])
+		})
+
+		entries := make([]string, 0, len(keys))
+		for _, key := range keys {
+			entries = append(entries, fmt.Sprintf("%s:%s", formatter.format(key, depth+1), formatter.format(v.MapIndex(key), depth+1)))
+		}
+
+		return "map[" + strings.Join(entries, " ") + "]"
+	}
+
+	return fmt.Sprintf("%+v", v)
+}
+
+// compareKeys returns true if the map key a sorts before b, in the same order
+// as the fmt package prints maps for the common kinds of key.
+func compareKeys(a reflect.Value, b reflect.Value) bool {
+	//exhaustive:ignore
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	case reflect.String:
+		return a.String() < b.String()
+	}
+
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
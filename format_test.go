@@ -0,0 +1,325 @@
+package chanassert_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hbomb79/go-chanassert"
+)
+
+type credentials struct {
+	User   string
+	Token  string `chanassert:"redact"`
+	Secret string `log:"secret"`
+}
+
+type login struct {
+	ID    int
+	Creds credentials
+	Roles []credentials
+	Meta  map[string]credentials
+	Note  any
+}
+
+type status int
+
+func (s status) String() string { return fmt.Sprintf("status-%d", int(s)) }
+
+func Test_MessageFormatter(t *testing.T) {
+	creds := credentials{User: "bob", Token: "t0k3n", Secret: "hunter2"}
+	message := login{
+		ID:    1,
+		Creds: creds,
+		Roles: []credentials{creds},
+		Meta:  map[string]credentials{"b": creds, "a": {User: "alice"}},
+		Note:  creds,
+	}
+
+	type plain struct {
+		Status status
+		Body   string
+	}
+
+	tests := []struct {
+		summary  string
+		actual   string
+		expected string
+	}{
+		{
+			summary:  "Without redacted fields",
+			actual:   chanassert.MessageFormatter[plain]().Format(plain{Status: 2, Body: "ok"}),
+			expected: fmt.Sprintf("%+v", plain{Status: 2, Body: "ok"}),
+		},
+		{
+			summary:  "Redacted by chanassert tag",
+			actual:   chanassert.MessageFormatter[credentials]().Format(creds),
+			expected: "{User:bob Token:<redacted> Secret:hunter2}",
+		},
+		{
+			summary:  "Redacted by name and tag",
+			actual:   chanassert.MessageFormatter[*credentials](chanassert.RedactFields("User"), chanassert.RedactTag("log", "secret")).Format(&creds),
+			expected: "&{User:<redacted> Token:<redacted> Secret:<redacted>}",
+		},
+		{
+			summary:  "Redacted by several tags with the same key",
+			actual:   chanassert.MessageFormatter[credentials](chanassert.RedactTag("log", "secret"), chanassert.RedactTag("log", "pii")).Format(creds),
+			expected: "{User:bob Token:<redacted> Secret:<redacted>}",
+		},
+		{
+			summary:  "Redacted by chanassert tag alongside another chanassert tag",
+			actual:   chanassert.MessageFormatter[credentials](chanassert.RedactTag("chanassert", "other")).Format(creds),
+			expected: "{User:bob Token:<redacted> Secret:hunter2}",
+		},
+		{
+			summary: "Nested redacted fields",
+			actual:  chanassert.MessageFormatter[login]().Format(message),
+			expected: "{ID:1 Creds:{User:bob Token:<redacted> Secret:hunter2} Roles:[{User:bob Token:<redacted> Secret:hunter2}] " +
+				"Meta:map[a:{User:alice Token:<redacted> Secret:} b:{User:bob Token:<redacted> Secret:hunter2}] Note:{User:bob Token:<redacted> Secret:hunter2}}",
+		},
+		{
+			summary:  "Truncated",
+			actual:   chanassert.MessageFormatter[string](chanassert.MaxLength(5)).Format("hello world"),
+			expected: "hello...",
+		},
+		{
+			summary:  "Not truncated",
+			actual:   chanassert.MessageFormatter[string](chanassert.MaxLength(5)).Format("hello"),
+			expected: "hello",
+		},
+	}
+
+	for _, test := range tests {
+		if test.actual != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.summary, test.expected, test.actual)
+		}
+	}
+}
+
+func Test_FormatMessages(t *testing.T) {
+	ch := make(chan credentials, 10)
+	exp := chanassert.NewChannelExpecter(ch).
+		Expect(chanassert.OneOf(chanassert.MatchStruct(credentials{User: "alice", Token: "s3cr3t"}))).
+		FormatMessages(chanassert.MessageFormatter[credentials](chanassert.RedactTag("log", "secret"), chanassert.MaxLength(40)))
+
+	exp.Listen()
+	ch <- credentials{User: "bob", Token: "t0k3n", Secret: "hunter2"}
+
+	builder := &strings.Builder{}
+	for _, err := range exp.AwaitSatisfied(time.Millisecond * 100) {
+		builder.WriteString(err.Error() + "\n")
+
+		var rejectErr chanassert.RejectionError[credentials]
+		if errors.As(err, &rejectErr) {
+			rejectErr.MessageResult.PrettyPrint(builder, true)
+		}
+	}
+	exp.FPrintTrace(builder)
+
+	output := builder.String()
+	for _, secret := range []string{"t0k3n", "s3cr3t", "hunter2"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("expected output to redact %q, but got:\n%s", secret, output)
+		}
+	}
+
+	for _, expected := range []string{
+		"message #0 ({User:bob Token:<redacted> Secret:<redac...) was unexpected by layer #0",
+		"Message '{User:bob Token:<redacted> Secret:<redac...' - REJECTED:",
+		"field Token: values differ (<redacted>)",
+		"field Secret: values differ (<redacted>)",
+		"deeply equals {User:alice Token:<redacted> Secret:<redacted>}",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %q, but got:\n%s", expected, output)
+		}
+	}
+}
+
+func Test_FormatMessages_RedactsMatchers(t *testing.T) {
+	type account struct {
+		Name  string
+		Token string
+	}
+
+	tests := []struct {
+		summary  string
+		matcher  chanassert.Matcher[account]
+		format   chanassert.Formatter[account]
+		expected []string
+		secrets  []string
+	}{
+		{
+			summary: "MatchStruct",
+			matcher: chanassert.MatchStruct(account{Name: "a", Token: "expected-secret"}),
+			format:  chanassert.MessageFormatter[account](chanassert.RedactFields("Token")),
+			expected: []string{
+				"deeply equals {Name:a Token:<redacted>}",
+				"field Token: values differ (<redacted>)",
+			},
+			secrets: []string{"expected-secret", "actual-secret"},
+		},
+		{
+			summary: "MatchStructFields",
+			matcher: chanassert.MatchStructFields[account](map[string]any{"Name": "a", "Token": "expected-secret"}),
+			format:  chanassert.MessageFormatter[account](chanassert.RedactFields("Token")),
+			expected: []string{
+				`has fields {Name="a", Token=<redacted>}`,
+				"field Token: values differ (<redacted>)",
+			},
+			secrets: []string{"expected-secret", "actual-secret"},
+		},
+		{
+			summary: "MatchStructFields predicate",
+			matcher: chanassert.MatchStructFields[account](map[string]any{"Token": func(string) bool { return false }}),
+			format:  chanassert.MessageFormatter[account](chanassert.RedactFields("Token")),
+			expected: []string{
+				"has fields {Token=<predicate>}",
+				"field Token: predicate returned false for <redacted>",
+			},
+			secrets: []string{"actual-secret"},
+		},
+		{
+			summary: "MatchEqual with a FormatterFunc",
+			matcher: chanassert.MatchEqual(account{Name: "a", Token: "expected-secret"}),
+			format:  chanassert.FormatterFunc[account](func(a account) string { return "account " + a.Name }),
+			expected: []string{
+				"equals account a",
+				"Message 'account a' - REJECTED",
+			},
+			// The fields redacted by a FormatterFunc are not known to the matchers
+			secrets: []string{"expected-secret"},
+		},
+	}
+
+	for _, test := range tests {
+		ch := make(chan account, 10)
+		exp := chanassert.NewChannelExpecter(ch).
+			Expect(chanassert.OneOf(test.matcher), chanassert.OneOf(chanassert.MatchStruct(account{Name: "b"}))).
+			FormatMessages(test.format)
+
+		exp.Listen()
+		ch <- account{Name: "a", Token: "actual-secret"}
+
+		builder := &strings.Builder{}
+		for _, err := range exp.AwaitSatisfied(time.Millisecond * 100) {
+			builder.WriteString(err.Error() + "\n")
+
+			var rejectErr chanassert.RejectionError[account]
+			if errors.As(err, &rejectErr) && rejectErr.NearMiss != nil {
+				builder.WriteString(rejectErr.NearMiss.String() + "\n")
+			}
+		}
+		exp.FPrintTrace(builder, chanassert.WithVerbosity(chanassert.LevelTrace))
+		builder.WriteString(exp.Describe())
+
+		output := builder.String()
+		for _, secret := range test.secrets {
+			if strings.Contains(output, secret) {
+				t.Errorf("%s: expected output to redact %q, but got:\n%s", test.summary, secret, output)
+			}
+		}
+
+		for _, expected := range test.expected {
+			if !strings.Contains(output, expected) {
+				t.Errorf("%s: expected output to contain %q, but got:\n%s", test.summary, expected, output)
+			}
+		}
+	}
+}
+
+func Test_FormatMessages_Nil(t *testing.T) {
+	for _, formatter := range []chanassert.Formatter[string]{nil, chanassert.FormatterFunc[string](nil)} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected FormatMessages(%#v) to panic", formatter)
+				}
+			}()
+
+			chanassert.NewChannelExpecter(make(chan string)).FormatMessages(formatter)
+		}()
+	}
+}
+
+func Test_FormatMessages_RedactsCaptures(t *testing.T) {
+	ch := make(chan credentials, 10)
+	exp := chanassert.NewChannelExpecter(ch).
+		Expect(chanassert.OneOf(
+			chanassert.Capture("user", func(c credentials) any { return c.User },
+				chanassert.Capture("token", func(c credentials) any { return c.Token },
+					chanassert.Capture("secret", func(c credentials) any { return c.Secret },
+						chanassert.Capture("creds", func(c credentials) any { return c }, chanassert.MatchAnything[credentials]()),
+					),
+				),
+			),
+		)).
+		FormatMessages(chanassert.MessageFormatter[credentials](chanassert.RedactTag("log", "secret")))
+
+	exp.Listen()
+	ch <- credentials{User: "bob", Token: "t0k3n", Secret: "hunter2"}
+	if errs := exp.AwaitSatisfied(time.Millisecond * 100); len(errs) > 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+
+	builder := &strings.Builder{}
+	exp.FPrintTrace(builder)
+	exp.FPrintTrace(builder, chanassert.WithRenderer(chanassert.CompactRenderer()))
+
+	export := exp.Export()
+	if err := chanassert.WriteHTML(builder, "captures", export); err != nil {
+		t.Fatalf("failed to write HTML: %v", err)
+	}
+	if err := chanassert.WriteChromeTrace(builder, export); err != nil {
+		t.Fatalf("failed to write Chrome trace: %v", err)
+	}
+
+	output := builder.String()
+	for _, secret := range []string{"t0k3n", "hunter2"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("expected output to redact %q, but got:\n%s", secret, output)
+		}
+	}
+
+	expected := "creds={User:bob Token:<redacted> Secret:<redacted>}, secret=<redacted>, token=<redacted>, user=bob"
+	if !strings.Contains(output, expected) {
+		t.Errorf("expected output to contain %q, but got:\n%s", expected, output)
+	}
+
+	if captures := exp.Captures(); captures["token"] != "t0k3n" {
+		t.Errorf("expected the captured value to be bound as-is, got %v", captures["token"])
+	}
+}
+
+func Test_FormatMessages_RedactsKeys(t *testing.T) {
+	ch := make(chan credentials, 10)
+	exp := chanassert.NewChannelExpecter(ch).
+		Expect(chanassert.ExactlyNOfEachKey(1, func(c credentials) string { return c.Secret }, chanassert.MatchAnything[credentials]())).
+		FormatMessages(chanassert.MessageFormatter[credentials](chanassert.RedactTag("log", "secret"))).
+		Debug()
+
+	exp.Listen()
+	ch <- credentials{User: "bob", Token: "t0k3n", Secret: "hunter2"}
+	if errs := exp.AwaitSatisfied(time.Millisecond * 100); len(errs) > 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+
+	builder := &strings.Builder{}
+	exp.FPrintTrace(builder)
+	if err := chanassert.WriteHTML(builder, "keys", exp.Export()); err != nil {
+		t.Fatalf("failed to write HTML: %v", err)
+	}
+
+	output := builder.String()
+	if strings.Contains(output, "hunter2") {
+		t.Errorf("expected output to redact the key, but got:\n%s", output)
+	}
+
+	for _, expected := range []string{"ACCEPT (key <redacted>)", "Key <redacted> => 1 message(s)", "Key &lt;redacted&gt;: 1 message(s)"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %q, but got:\n%s", expected, output)
+		}
+	}
+}
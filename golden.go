@@ -0,0 +1,203 @@
+package chanassert
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultGoldenDir is the directory, relative to the package under test, in
+// which [AssertTraceGolden] stores golden traces unless [GoldenDir] is provided.
+const DefaultGoldenDir = "testdata/traces"
+
+// goldenContext is the number of unchanged lines shown either side of each change in a golden diff.
+const goldenContext = 3
+
+// GoldenUpdateEnv is the environment variable which, when set to a true value (e.g. CHANASSERT_UPDATE=1),
+// causes [AssertTraceGolden] to overwrite golden traces with the traces produced by the tests.
+const GoldenUpdateEnv = "CHANASSERT_UPDATE"
+
+// GoldenT is the subset of [testing.T] required by [AssertTraceGolden].
+type GoldenT interface {
+	TestingT
+	Helper()
+	Name() string
+	Fatalf(format string, args ...any)
+}
+
+type goldenConfig struct {
+	dir       string
+	update    bool
+	normalize bool
+}
+
+// GoldenOption configures the behaviour of [AssertTraceGolden].
+type GoldenOption func(*goldenConfig)
+
+// GoldenDir stores the golden traces within the directory provided, rather than [DefaultGoldenDir].
+func GoldenDir(dir string) GoldenOption {
+	return func(config *goldenConfig) {
+		config.dir = dir
+	}
+}
+
+// NormalizeGolden normalises the trace (see [NormalizeTrace]) before it is compared with the golden
+// trace, in the same way as calling Normalize on the expecter (see [Expecter.Normalize]).
+func NormalizeGolden() GoldenOption {
+	return func(config *goldenConfig) {
+		config.normalize = true
+	}
+}
+
+// UpdateGolden overwrites the golden trace with the trace of the expecter if update is true, in the same
+// way as setting [GoldenUpdateEnv]. This allows a test suite to control updates using its own flag:
+//
+//	var update = flag.Bool("update", false, "update golden traces")
+//	...
+//	chanassert.AssertTraceGolden(t, exp, chanassert.UpdateGolden(*update))
+func UpdateGolden(update bool) GoldenOption {
+	return func(config *goldenConfig) {
+		config.update = config.update || update
+	}
+}
+
+// AssertTraceGolden asserts that the trace of the expecter (as printed by FPrintTrace) matches the
+// golden trace stored for the test, failing the test with a line-by-line diff if it does not. The
+// golden trace is stored in a file named after the test (including any subtests) within the golden
+// directory (see [DefaultGoldenDir] and [GoldenDir]).
+//
+// Running the tests with CHANASSERT_UPDATE=1 (see [GoldenUpdateEnv] and [UpdateGolden]) writes the golden
+// traces of every test which calls AssertTraceGolden, e.g. when adding a test or after an intentional change
+// to the expectations. Otherwise, a missing golden trace fails the test (showing the trace of the expecter),
+// so that a golden trace which was never committed, or was misnamed, cannot pass unnoticed.
+//
+// The trace is compared as printed, and so is only normalised (see [NormalizeTrace]) if the expecter
+// normalises its trace (see [Expecter.Normalize]) or [NormalizeGolden] is provided. Normalising the trace
+// keeps golden traces stable from run to run when the messages contain memory addresses or durations. As
+// the trace only includes debug information when the expecter is in Debug mode, the expecter must be in
+// the same mode as it was when the golden trace was written.
+func AssertTraceGolden[T any](t GoldenT, expecter Expecter[T], opts ...GoldenOption) {
+	t.Helper()
+
+	update, _ := strconv.ParseBool(os.Getenv(GoldenUpdateEnv))
+	config := goldenConfig{dir: DefaultGoldenDir, update: update}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	builder := &strings.Builder{}
+	expecter.FPrintTrace(builder)
+	actual := builder.String()
+	if config.normalize {
+		actual = NormalizeTrace(actual)
+	}
+	actual = strings.TrimSpace(actual)
+
+	path := filepath.Join(config.dir, filepath.FromSlash(t.Name()))
+	if config.update {
+		if err := writeGolden(path, actual); err != nil {
+			t.Fatalf("failed to update golden trace: %s", err)
+			return
+		}
+
+		t.Logf("updated golden trace %q", path)
+		return
+	}
+
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("golden trace %q does not exist (rerun with %s=1 to create it)\nExpecter trace follows:\n%s\n", path, GoldenUpdateEnv, actual)
+		return
+	} else if err != nil {
+		t.Fatalf("failed to read golden trace %q: %s\nExpecter trace follows:\n%s\n", path, err, actual)
+		return
+	}
+
+	expected := strings.TrimSpace(string(contents))
+	if expected != actual {
+		t.Fatalf("trace did not match golden trace %q (rerun with %s=1 to accept the changes):\n%s",
+			path, GoldenUpdateEnv, diffLines(strings.Split(expected, "\n"), strings.Split(actual, "\n")))
+	}
+}
+
+func writeGolden(path string, trace string) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, []byte(trace+"\n"), 0o644)
+}
+
+// diffLines returns a diff of the lines provided, in which removed lines are prefixed with
+// '-', and added lines with '+'. Only the lines surrounding each change are included.
+func diffLines(expected []string, actual []string) string {
+	// lcs[i][j] holds the length of the longest common
+	// subsequence of expected[i:] and actual[j:]
+	lcs := make([][]int, len(expected)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(actual)+1)
+	}
+
+	for i := len(expected) - 1; i >= 0; i-- {
+		for j := len(actual) - 1; j >= 0; j-- {
+			if expected[i] == actual[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type diffLine struct {
+		prefix string
+		line   string
+	}
+
+	lines := make([]diffLine, 0, len(expected)+len(actual))
+	i, j := 0, 0
+	for i < len(expected) || j < len(actual) {
+		switch {
+		case i < len(expected) && j < len(actual) && expected[i] == actual[j]:
+			lines = append(lines, diffLine{" ", expected[i]})
+			i++
+			j++
+		case i < len(expected) && (j == len(actual) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, diffLine{"-", expected[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{"+", actual[j]})
+			j++
+		}
+	}
+
+	// Only include unchanged lines which are close to a change
+	shown := make([]bool, len(lines))
+	for idx, l := range lines {
+		if l.prefix == " " {
+			continue
+		}
+
+		for near := max(0, idx-goldenContext); near <= min(len(lines)-1, idx+goldenContext); near++ {
+			shown[near] = true
+		}
+	}
+
+	builder := &strings.Builder{}
+	for idx, l := range lines {
+		if !shown[idx] {
+			if idx > 0 && shown[idx-1] {
+				builder.WriteString("  ...\n")
+			}
+
+			continue
+		}
+
+		fmt.Fprintf(builder, "%s %s\n", l.prefix, l.line)
+	}
+
+	return builder.String()
+}
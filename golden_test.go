@@ -0,0 +1,160 @@
+package chanassert_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hbomb79/go-chanassert"
+)
+
+// mockGoldenT records the outcome of AssertTraceGolden, for the test name provided.
+type mockGoldenT struct {
+	mockTestingT
+	name  string
+	fatal string
+}
+
+func (mock *mockGoldenT) Helper()      {}
+func (mock *mockGoldenT) Name() string { return mock.name }
+
+func (mock *mockGoldenT) Fatalf(format string, args ...any) {
+	mock.fatal = strings.TrimSpace(fmt.Sprintf(format, args...))
+}
+
+func makeGoldenExpecter(messages ...string) chanassert.Expecter[string] {
+	ch := make(chan string, len(messages))
+	exp := chanassert.NewChannelExpecter(ch).Expect(chanassert.AtLeastNOf(2, chanassert.MatchEqual("a"), chanassert.MatchEqual("b")))
+	exp.Listen()
+	for _, m := range messages {
+		ch <- m
+	}
+
+	exp.AwaitSatisfied(time.Millisecond * 100)
+	return exp
+}
+
+func Test_AssertTraceGolden(t *testing.T) {
+	t.Setenv(chanassert.GoldenUpdateEnv, "")
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Test_Golden", "subtest")
+
+	// A missing golden trace fails the test, showing the trace
+	mock := &mockGoldenT{name: "Test_Golden/subtest"}
+	chanassert.AssertTraceGolden(mock, makeGoldenExpecter("a", "b"), chanassert.GoldenDir(dir))
+	if !strings.Contains(mock.fatal, "does not exist") || !strings.Contains(mock.fatal, "Message 'a' - ACCEPTED:") {
+		t.Fatalf("expected missing golden trace to fail the test with the trace, got fatal=%q", mock.fatal)
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		t.Fatalf("expected missing golden trace not to be created outside of update mode")
+	}
+
+	// The update option creates the missing golden trace
+	mock = &mockGoldenT{name: "Test_Golden/subtest"}
+	chanassert.AssertTraceGolden(mock, makeGoldenExpecter("a", "b"), chanassert.GoldenDir(dir), chanassert.UpdateGolden(true))
+	if mock.fatal != "" {
+		t.Fatalf("expected creating golden trace to pass, got fatal=%q", mock.fatal)
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected golden trace to be created: %s", err)
+	}
+
+	if !strings.HasPrefix(string(contents), "Message 'a' - ACCEPTED:") {
+		t.Errorf("unexpected golden trace created:\n%s", contents)
+	}
+
+	// A matching trace passes
+	mock = &mockGoldenT{name: "Test_Golden/subtest"}
+	chanassert.AssertTraceGolden(mock, makeGoldenExpecter("a", "b"), chanassert.GoldenDir(dir))
+	if mock.fatal != "" {
+		t.Fatalf("expected matching golden trace to pass, got fatal=%q", mock.fatal)
+	}
+
+	// A differing trace fails with a diff
+	mock = &mockGoldenT{name: "Test_Golden/subtest"}
+	chanassert.AssertTraceGolden(mock, makeGoldenExpecter("a", "a"), chanassert.GoldenDir(dir))
+	expected := strings.Join([]string{
+		`- Message 'b' - ACCEPTED:`,
+		`+ Message 'a' - ACCEPTED:`,
+		`    - Layer #0 matched message against combiner #0`,
+		`-     * Combiner #0: Combiner matched on matcher #1`,
+		`-       + Matcher #0 (equals "a") REJECT: no match`,
+		`-       + Matcher #1 (equals "b") ACCEPT`,
+		`+     * Combiner #0: Combiner matched on matcher #0`,
+		`+       + Matcher #0 (equals "a") ACCEPT`,
+	}, "\n")
+	if !strings.Contains(mock.fatal, "rerun with CHANASSERT_UPDATE=1") || !strings.Contains(mock.fatal, expected) {
+		t.Errorf("expected differing golden trace to fail with diff:\n%s\nbut got:\n%s", expected, mock.fatal)
+	}
+
+	// The update option overwrites the golden trace
+	mock = &mockGoldenT{name: "Test_Golden/subtest"}
+	chanassert.AssertTraceGolden(mock, makeGoldenExpecter("a", "a"), chanassert.GoldenDir(dir), chanassert.UpdateGolden(true))
+	if mock.fatal != "" {
+		t.Fatalf("expected updating golden trace to pass, got fatal=%q", mock.fatal)
+	}
+
+	if updated, _ := os.ReadFile(path); strings.Contains(string(updated), "Message 'b'") {
+		t.Errorf("expected golden trace to be updated, got:\n%s", updated)
+	}
+
+	// As does the update environment variable
+	t.Setenv(chanassert.GoldenUpdateEnv, "1")
+	mock = &mockGoldenT{name: "Test_Golden/subtest"}
+	chanassert.AssertTraceGolden(mock, makeGoldenExpecter("a", "b"), chanassert.GoldenDir(dir))
+	if mock.fatal != "" {
+		t.Fatalf("expected updating golden trace to pass, got fatal=%q", mock.fatal)
+	}
+
+	if updated, _ := os.ReadFile(path); !strings.Contains(string(updated), "Message 'b'") {
+		t.Errorf("expected golden trace to be updated, got:\n%s", updated)
+	}
+}
+
+func Test_AssertTraceGolden_Normalize(t *testing.T) {
+	tests := []struct {
+		summary  string
+		expecter chanassert.Expecter[string]
+		opts     []chanassert.GoldenOption
+		expected string
+	}{
+		{
+			summary:  "Not normalised by default",
+			expecter: makeGoldenExpecter("5ms"),
+			expected: "Message '5ms' - REJECTED",
+		},
+		{
+			summary:  "Normalised by option",
+			expecter: makeGoldenExpecter("5ms"),
+			opts:     []chanassert.GoldenOption{chanassert.NormalizeGolden()},
+			expected: "Message '<duration>' - REJECTED",
+		},
+		{
+			summary:  "Normalised by expecter",
+			expecter: makeGoldenExpecter("5ms").Normalize(),
+			expected: "Message '<duration>' - REJECTED",
+		},
+	}
+
+	dir := t.TempDir()
+	for idx, test := range tests {
+		name := fmt.Sprintf("Test_Golden/normalize-%d", idx)
+		opts := append(test.opts, chanassert.GoldenDir(dir), chanassert.UpdateGolden(true))
+		chanassert.AssertTraceGolden(&mockGoldenT{name: name}, test.expecter, opts...)
+
+		contents, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatalf("%s: expected golden trace to be written: %s", test.summary, err)
+		}
+
+		if !strings.HasPrefix(string(contents), test.expected) {
+			t.Errorf("%s: expected golden trace to begin with %q, got:\n%s", test.summary, test.expected, contents)
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package chanassert
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type htmlReport struct {
+	Title    string
+	Layers   []htmlLayer
+	Ignore   []string
+	Messages []htmlMessage
+	Errors   []ExportError
+}
+
+type htmlLayer struct {
+	Label       string
+	Description string
+	Status      string
+	Combiners   []htmlCombiner
+	Shortfall   []string
+}
+
+// htmlCombiner is a row of the table of combiners shown under each layer, flattened from the
+// [CombinerReport] of the layer. Depth is the nesting of the combiner within composite combiners.
+type htmlCombiner struct {
+	Depth       int
+	Label       string
+	Description string
+	Mode        string
+	Bounds      string
+	Matched     string
+	Counts      []string
+	Status      string
+	Satisfied   bool
+	Shortfall   []string
+}
+
+type htmlMessage struct {
+	Num      int
+	Message  string
+	Status   string
+	Layer    string
+	Summary  string
+	Captures string
+	Trace    TraceMessage
+	Progress []string
+}
+
+// WriteHTML writes the [Export] provided to the writer as a single, self-contained HTML page (with no
+// external assets), which is far easier to navigate than the text trace of a long run. The page contains a
+// timeline of the messages coloured by their status, the collapsible trace of each message, the progress
+// of each layer as of each message, the state of each layer and its combiners, and the errors reported
+// by the expecter. Messages can be searched by their content and trace.
+//
+// Each layer is followed by a table of its combiners (from its [LayerReport]), with the bounds of each
+// combiner, the number of messages it matched, and its shortfall. The progress shown for each message
+// is the number of messages accepted by each layer up to, and including, that message; the state of the
+// combiners as of each message is included in its trace at [LevelTrace].
+func WriteHTML[T any](w io.Writer, title string, export Export[T]) error {
+	report := htmlReport{Title: title, Ignore: export.Ignore, Errors: export.Errors}
+	for idx, outcome := range layerOutcomes(export) {
+		layer := export.Layers[idx]
+		status := "satisfied"
+		switch {
+		case len(outcome.errors) > 0:
+			status = "failed"
+		case outcome.skipped:
+			status = "skipped"
+		case !layer.Satisfied:
+			status = "unsatisfied"
+		}
+
+		htmlLayer := htmlLayer{Label: outcome.label, Description: layer.Description, Status: status}
+		if layer.Report != nil {
+			htmlLayer.Combiners = htmlCombiners(layer.Report.Combiners, 0)
+			htmlLayer.Shortfall = layer.Report.Shortfall
+		}
+
+		report.Layers = append(report.Layers, htmlLayer)
+	}
+
+	accepted := make([]int, len(export.Layers))
+	for idx, m := range export.Messages {
+		message := htmlMessage{
+			Num:     idx,
+			Message: m.formatted(),
+			Status:  m.Status.String(),
+			Layer:   "-",
+			Summary: m.Trace.Message,
+			Trace:   m.Trace,
+		}
+
+		if len(m.Captures) > 0 {
+			message.Captures = m.captures().String()
+		}
+
+		if m.LayerIdx >= 0 && m.LayerIdx < len(accepted) {
+			message.Layer = nameLabel("Layer", m.LayerIdx, m.LayerName)
+			if m.Status == Accepted {
+				accepted[m.LayerIdx]++
+			}
+		}
+
+		for layerIdx, count := range accepted {
+			if count > 0 {
+				message.Progress = append(message.Progress, fmt.Sprintf("%s: %s accepted", report.Layers[layerIdx].Label, formatCount(count)))
+			}
+		}
+
+		report.Messages = append(report.Messages, message)
+	}
+
+	return htmlTemplate.Execute(w, report)
+}
+
+// htmlCombiners flattens the reports provided, and those of any combiners nested within them,
+// in to the rows of the table of combiners of a layer.
+func htmlCombiners(reports []CombinerReport, depth int) []htmlCombiner {
+	rows := make([]htmlCombiner, 0, len(reports))
+	for _, report := range reports {
+		row := htmlCombiner{
+			Depth:       depth,
+			Label:       report.Label,
+			Description: report.Description,
+			Mode:        report.Mode,
+			Bounds:      "-",
+			Matched:     "-",
+			Status:      report.status(),
+			Satisfied:   report.Satisfied,
+			Shortfall:   report.Shortfall,
+		}
+
+		if len(report.Combiners) == 0 && (report.Min > 0 || report.Max > 0) {
+			row.Bounds = formatBounds(report.Min, report.Max)
+		}
+
+		if len(report.Matchers) > 0 || len(report.Keys) > 0 {
+			matched := 0
+			for _, matcher := range report.Matchers {
+				matched += matcher.Count
+				row.Counts = append(row.Counts, fmt.Sprintf("%s (%s): %s", matcher.Label, matcher.Description, formatCount(matcher.Count)))
+			}
+
+			for _, key := range report.Keys {
+				if len(report.Matchers) == 0 {
+					matched += key.Count
+				}
+				row.Counts = append(row.Counts, fmt.Sprintf("Key %s: %s", key.Key, formatCount(key.Count)))
+			}
+
+			row.Matched = formatCount(matched)
+		}
+
+		rows = append(rows, row)
+		rows = append(rows, htmlCombiners(report.Combiners, depth+1)...)
+	}
+
+	return rows
+}
+
+// formatBounds describes the bounds of a combiner (e.g. "exactly 2", "at least 1" or "1 to 3").
+func formatBounds(min, max int) string {
+	switch {
+	case max == math.MaxInt:
+		return fmt.Sprintf("at least %d", min)
+	case min == max:
+		return fmt.Sprintf("exactly %d", min)
+	default:
+		return fmt.Sprintf("%d to %d", min, max)
+	}
+}
+
+// WithHTMLReport writes an HTML report of the expecter (see [WriteHTML]) to a new file within the directory
+// provided when AssertSatisfied fails, and logs the path of the file. The file is named after the test when
+// the [TestingT] provided to AssertSatisfied has a Name method (as [testing.T] does). This option only
+// affects AssertSatisfied.
+func WithHTMLReport(dir string) TraceOption {
+	return func(config *traceConfig) {
+		config.htmlDir = dir
+	}
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// writeHTMLReport writes the HTML report of the expecter to a new file within the directory
+// provided, returning the path of the file.
+func writeHTMLReport[T any](dir string, name string, export Export[T]) (string, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", err
+	}
+
+	file, err := os.CreateTemp(dir, unsafeFileChars.ReplaceAllString(name, "_")+"-*.html")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if err := WriteHTML(file, name, export); err != nil {
+		return "", err
+	}
+
+	return filepath.Clean(file.Name()), file.Close()
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"lower": strings.ToLower,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 1.5em; color: #222; }
+h1 { font-size: 1.4em; }
+h2 { font-size: 1.15em; margin-top: 1.5em; }
+code, .trace, .message-text { font-family: monospace; }
+.accepted { --status: #2e7d32; }
+.rejected { --status: #c62828; }
+.ignored { --status: #9e9e9e; }
+.satisfied { --status: #2e7d32; }
+.failed, .unsatisfied { --status: #c62828; }
+.skipped { --status: #9e9e9e; }
+.badge { display: inline-block; min-width: 6em; padding: 0 .4em; color: #fff; background: var(--status); border-radius: 3px; font-size: .85em; text-align: center; }
+#timeline { display: flex; flex-wrap: wrap; gap: 2px; }
+#timeline a { display: block; width: 12px; height: 24px; background: var(--status); }
+#timeline a.hidden { opacity: .15; }
+#search { width: 30em; padding: .3em; }
+.layer, .message { border-left: 4px solid var(--status); margin: .5em 0; padding: .2em .6em; }
+.message.hidden { display: none; }
+.progress { color: #555; font-size: .85em; margin: .2em 0; }
+.trace { font-size: .9em; }
+.combiners { border-collapse: collapse; margin: .4em 0; font-size: .9em; }
+.combiners th, .combiners td { border-bottom: 1px solid #ddd; padding: .2em .6em; text-align: left; vertical-align: top; }
+.combiners tr.unsatisfied td:first-child { border-left: 3px solid #c62828; }
+.combiners tr.satisfied td:first-child { border-left: 3px solid #2e7d32; }
+.trace details, .trace .leaf { margin-left: 1.2em; }
+.trace .level-debug, .trace .level-trace { color: #777; }
+.trace .level-error { color: #c62828; }
+.errors li { white-space: pre-wrap; font-family: monospace; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+
+<h2>Layers</h2>
+{{range .Layers}}<div class="layer {{.Status}}">
+<span class="badge">{{.Status}}</span> <strong>{{.Label}}</strong>: {{.Description}}
+{{with .Combiners}}<table class="combiners">
+<tr><th>Combiner</th><th>Mode</th><th>Bounds</th><th>Matched</th><th>Status</th><th>Shortfall</th></tr>
+{{range .}}<tr class="{{if .Satisfied}}satisfied{{else}}unsatisfied{{end}}">
+<td style="padding-left: {{.Depth}}.5em"><strong>{{.Label}}</strong>: {{.Description}}</td><td>{{.Mode}}</td><td>{{.Bounds}}</td>
+<td>{{.Matched}}{{range .Counts}}<div class="progress">{{.}}</div>{{end}}</td><td>{{.Status}}</td>
+<td>{{range .Shortfall}}<div>{{.}}</div>{{end}}</td></tr>
+{{end}}</table>
+{{end}}{{range .Shortfall}}<div class="progress">Shortfall: {{.}}</div>
+{{end}}</div>
+{{end}}{{if .Ignore}}<p>Ignoring: {{range $idx, $ignore := .Ignore}}{{if $idx}}, {{end}}<code>{{$ignore}}</code>{{end}}</p>
+{{end}}
+{{if .Errors}}<h2>Errors</h2>
+<ul class="errors">
+{{range .Errors}}<li>[{{.Kind}}] {{.Error}}{{with .NearMiss}}
+Near miss: {{.}}{{end}}</li>
+{{end}}</ul>
+{{end}}
+<h2>Messages ({{len .Messages}})</h2>
+<div id="timeline">
+{{range .Messages}}<a href="#message-{{.Num}}" class="{{lower .Status}}" data-num="{{.Num}}" title="#{{.Num}} {{.Status}}: {{.Message}}"></a>
+{{end}}</div>
+<p><input id="search" type="search" placeholder="Search messages and traces"></p>
+{{range .Messages}}<div id="message-{{.Num}}" class="message {{lower .Status}}" data-num="{{.Num}}">
+<details>
+<summary><span class="badge">{{.Status}}</span> #{{.Num}} <span class="message-text">'{{.Message}}'</span> ({{.Layer}}): {{.Summary}}{{with .Captures}} (captured {{.}}){{end}}</summary>
+{{if .Progress}}<div class="progress">{{range $idx, $progress := .Progress}}{{if $idx}}; {{end}}{{$progress}}{{end}}</div>
+{{end}}<div class="trace">{{template "trace" .Trace}}</div>
+</details>
+</div>
+{{end}}
+<script>
+(function () {
+	var search = document.getElementById("search");
+	search.addEventListener("input", function () {
+		var query = search.value.toLowerCase();
+		document.querySelectorAll(".message").forEach(function (message) {
+			var hidden = query !== "" && message.textContent.toLowerCase().indexOf(query) === -1;
+			message.classList.toggle("hidden", hidden);
+			document.querySelector('#timeline a[data-num="' + message.dataset.num + '"]').classList.toggle("hidden", hidden);
+		});
+	});
+
+	document.querySelectorAll("#timeline a").forEach(function (link) {
+		link.addEventListener("click", function () {
+			document.querySelector("#message-" + link.dataset.num + " > details").open = true;
+		});
+	});
+})();
+</script>
+</body>
+</html>
+{{define "trace"}}{{if .Nested}}<details open class="level-{{.Mode}}"><summary>{{.Message}}</summary>
+{{range .Nested}}{{template "trace" .}}{{end}}</details>
+{{else}}<div class="leaf level-{{.Mode}}">{{.Message}}</div>
+{{end}}{{end}}`))
@@ -0,0 +1,103 @@
+package chanassert_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hbomb79/go-chanassert"
+)
+
+func Test_WriteHTML(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	if err := chanassert.WriteHTML(buffer, "conformance", makeReportedExpecter().Export()); err != nil {
+		t.Fatalf("unexpected error writing HTML report: %s", err)
+	}
+
+	report := buffer.String()
+	for _, expected := range []string{
+		"<title>conformance</title>",
+		`<span class="badge">failed</span> <strong>Layer &#39;greeting&#39;</strong>`,
+		`<span class="badge">failed</span> <strong>Layer #1</strong>`,
+		`<span class="badge">skipped</span> <strong>Layer #2</strong>`,
+		`<a href="#message-0" class="rejected" data-num="0"`,
+		`<div id="message-1" class="message accepted" data-num="1">`,
+		"Layer &#39;greeting&#39;: 1 message(s) accepted; Layer #1: 1 message(s) accepted",
+		"Shortfall: needs combiner #0 to be satisfied",
+		`<td>SUM</td><td>exactly 2</td>`,
+		`<td>1 message(s)<div class="progress">Matcher #0 (equals &#34;world&#34;): 1 message(s)</div></td><td>NOT satisfied, NOT saturated</td>`,
+		"<div>needs 1 more message(s) matching any matcher</div>",
+		"Near miss: would have matched layer #1",
+		`<input id="search"`,
+	} {
+		if !strings.Contains(report, expected) {
+			t.Errorf("expected HTML report to contain %q, got:\n%s", expected, report)
+		}
+	}
+
+	// The report must be self-contained
+	for _, external := range []string{"src=", "<link", "http://", "https://"} {
+		if strings.Contains(report, external) {
+			t.Errorf("expected HTML report not to reference external assets, found %q", external)
+		}
+	}
+}
+
+func Test_WriteHTML_EscapesMessages(t *testing.T) {
+	ch := make(chan string, 1)
+	exp := chanassert.NewChannelExpecter(ch).Expect(chanassert.OneOf(chanassert.MatchEqual("hello")))
+	exp.Listen()
+	ch <- "<script>alert(1)</script>"
+	exp.AwaitSatisfied(time.Millisecond * 100)
+
+	buffer := &bytes.Buffer{}
+	if err := chanassert.WriteHTML(buffer, "escaping", exp.Export()); err != nil {
+		t.Fatalf("unexpected error writing HTML report: %s", err)
+	}
+
+	if strings.Contains(buffer.String(), "<script>alert(1)") {
+		t.Errorf("expected message to be escaped in HTML report, got:\n%s", buffer)
+	}
+}
+
+func Test_WithHTMLReport(t *testing.T) {
+	dir := t.TempDir()
+
+	// No report is written when the expecter is satisfied
+	ch := make(chan string, 1)
+	exp := chanassert.NewChannelExpecter(ch).Expect(chanassert.OneOf(chanassert.MatchEqual("hello")))
+	exp.Listen()
+	ch <- "hello"
+	exp.AssertSatisfied(&mockGoldenT{name: "Test_Report/passing"}, time.Millisecond*100, chanassert.WithHTMLReport(dir))
+
+	mock := &mockGoldenT{name: "Test_Report/failing"}
+	makeReportedExpecter().AssertSatisfied(mock, time.Millisecond*100, chanassert.WithHTMLReport(dir))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.html"))
+	if err != nil || len(files) != 1 || !strings.HasPrefix(filepath.Base(files[0]), "Test_Report_failing-") {
+		t.Fatalf("expected a single HTML report for the failing test, got %v (%v)", files, err)
+	}
+
+	contents, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("unexpected error reading HTML report: %s", err)
+	}
+
+	if !strings.Contains(string(contents), "<title>Test_Report/failing</title>") {
+		t.Errorf("expected HTML report to be titled after the test, got:\n%s", contents)
+	}
+
+	logged := false
+	for _, seen := range mock.seen {
+		if !seen.wasErr && strings.Contains(seen.message, "expecter HTML report written to "+files[0]) {
+			logged = true
+		}
+	}
+
+	if !logged {
+		t.Errorf("expected the path of the HTML report to be logged, got %v", mock.seen)
+	}
+}
@@ -0,0 +1,457 @@
+package chanassert
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// AtLeastNDistinct accepts a number, n, a key function and a list of matchers. The returned
+// combiner will become satisfied once messages with AT LEAST N distinct keys (as returned by
+// the key function) have been matched. Each key may only be matched once; a message with a key
+// which has already been matched is rejected as a duplicate.
+func AtLeastNDistinct[T any, K comparable](n int, key func(T) K, matchers ...Matcher[T]) *keyedCombiner[T, K] {
+	return newKeyedCombiner(modeSum, n, math.MaxInt, key, matchers)
+}
+
+// BetweenNDistinct accepts two numbers, min and max, a key function and a list of matchers. The
+// returned combiner will become satisfied once messages with AT LEAST MIN and NO MORE THAN MAX distinct
+// keys have been matched. Each key may only be matched once.
+func BetweenNDistinct[T any, K comparable](min int, max int, key func(T) K, matchers ...Matcher[T]) *keyedCombiner[T, K] {
+	return newKeyedCombiner(modeSum, min, max, key, matchers)
+}
+
+// ExactlyNDistinct accepts a number, n, a key function and a list of matchers. The returned
+// combiner will become satisfied once messages with EXACTLY N distinct keys have been
+// matched. Each key may only be matched once.
+func ExactlyNDistinct[T any, K comparable](n int, key func(T) K, matchers ...Matcher[T]) *keyedCombiner[T, K] {
+	return newKeyedCombiner(modeSum, n, n, key, matchers)
+}
+
+// AtLeastNOfEachKey accepts a number, n, a key function and a list of matchers. The returned
+// combiner will become satisfied once EACH key seen (or each of the keys required, see WithKeys)
+// has been matched AT LEAST N times.
+func AtLeastNOfEachKey[T any, K comparable](n int, key func(T) K, matchers ...Matcher[T]) *keyedCombiner[T, K] {
+	return newKeyedCombiner(modeEach, n, math.MaxInt, key, matchers)
+}
+
+// BetweenNOfEachKey accepts two numbers, min and max, a key function and a list of matchers. The
+// returned combiner will become satisfied once EACH key seen (or each of the keys required, see WithKeys)
+// has been matched AT LEAST MIN times, and NO MORE THAN MAX times.
+func BetweenNOfEachKey[T any, K comparable](min int, max int, key func(T) K, matchers ...Matcher[T]) *keyedCombiner[T, K] {
+	return newKeyedCombiner(modeEach, min, max, key, matchers)
+}
+
+// ExactlyNOfEachKey accepts a number, n, a key function and a list of matchers. The returned
+// combiner will become satisfied once EACH key seen (or each of the keys required, see WithKeys)
+// has been matched EXACTLY N times.
+func ExactlyNOfEachKey[T any, K comparable](n int, key func(T) K, matchers ...Matcher[T]) *keyedCombiner[T, K] {
+	return newKeyedCombiner(modeEach, n, n, key, matchers)
+}
+
+// AtLeastNOfAnyKey accepts a number, n, a key function and a list of matchers. The returned
+// combiner will become satisfied once ANY key has been matched AT LEAST N times.
+func AtLeastNOfAnyKey[T any, K comparable](n int, key func(T) K, matchers ...Matcher[T]) *keyedCombiner[T, K] {
+	return newKeyedCombiner(modeAny, n, math.MaxInt, key, matchers)
+}
+
+// BetweenNOfAnyKey accepts two numbers, min and max, a key function and a list of matchers. The
+// returned combiner will become satisfied once ANY key has been matched AT LEAST MIN times, and
+// NO MORE THAN MAX times.
+func BetweenNOfAnyKey[T any, K comparable](min int, max int, key func(T) K, matchers ...Matcher[T]) *keyedCombiner[T, K] {
+	return newKeyedCombiner(modeAny, min, max, key, matchers)
+}
+
+// ExactlyNOfAnyKey accepts a number, n, a key function and a list of matchers. The returned
+// combiner will become satisfied once ANY key has been matched EXACTLY N times.
+func ExactlyNOfAnyKey[T any, K comparable](n int, key func(T) K, matchers ...Matcher[T]) *keyedCombiner[T, K] {
+	return newKeyedCombiner(modeAny, n, n, key, matchers)
+}
+
+func newKeyedCombiner[T any, K comparable](mode mode, min int, max int, key func(T) K, matchers []Matcher[T]) *keyedCombiner[T, K] {
+	return &keyedCombiner[T, K]{mode: mode, min: min, max: max, key: key, matchers: matchers, counts: make(map[K]int), formatted: make(map[K]string)}
+}
+
+type keyedCombiner[T any, K comparable] struct {
+	CombinerBase
+
+	matchers []Matcher[T]
+	key      func(T) K
+	min      int
+	max      int
+	mode     mode
+
+	// counts holds the number of messages matched for each key, and
+	// seen holds the same keys in the order they were first matched.
+	counts map[K]int
+	seen   []K
+
+	// required is the set of keys which must be matched (see WithKeys). If
+	// nil, then any key is accepted.
+	required []K
+
+	// duplicates holds the keys (in the order they were first rejected) of
+	// messages which were rejected as the key had already matched the maximum.
+	duplicates []K
+
+	// formatted holds each key taken from a message, formatted by the formatter of the
+	// expecter (see formatKey), so that keys holding redacted values remain redacted.
+	formatted map[K]string
+}
+
+// WithKeys restricts the combiner to ONLY the keys provided. Messages with
+// any other key will be rejected, and the combiner will only be satisfied once
+// the requirement of the combiner is met for the keys provided (e.g. each key must
+// be matched when using [ExactlyNOfEachKey]).
+func (keyed *keyedCombiner[T, K]) WithKeys(keys ...K) *keyedCombiner[T, K] {
+	keyed.required = keys
+	return keyed
+}
+
+// Named names this combiner, so that it is referred to by name (rather
+// than by its index) in traces and errors.
+func (keyed *keyedCombiner[T, K]) Named(name string) *keyedCombiner[T, K] {
+	keyed.SetName(name)
+	return keyed
+}
+
+func (keyed *keyedCombiner[T, K]) tryMatch(message T, scope *captureScope) (bool, TraceMessage) {
+	if keyed.saturated {
+		return false, newInfoTrace("Combiner is fully saturated, accepting no further messages")
+	}
+
+	attempts := make([]TraceMessage, 0)
+	for i, m := range keyed.matchers {
+		if ok, reason := explainMatch(m, message, scope); !ok {
+			attempts = append(attempts, newErrorTrace(fmt.Sprintf("%s REJECT: %s", matcherLabel(i, m, scope.formatter()), reason)))
+			continue
+		}
+
+		key := keyed.key(message)
+		keyed.formatKey(message, key, scope.formatter())
+		if keyed.required != nil && !keyed.isRequired(key) {
+			attempts = append(attempts, newErrorTrace(fmt.Sprintf("%s REJECT: key %s is not one of the required keys %s", matcherLabel(i, m, scope.formatter()), keyed.keyString(key, scope.formatter()), keyed.keysString(keyed.required, scope.formatter()))))
+			continue
+		}
+
+		if keyed.counts[key] >= keyed.keyMax() {
+			keyed.addDuplicate(key)
+			attempts = append(attempts, newErrorTrace(fmt.Sprintf("%s REJECT: duplicate key %s has already matched maximum allowed messages (%d)", matcherLabel(i, m, scope.formatter()), keyed.keyString(key, scope.formatter()), keyed.keyMax())))
+			continue
+		}
+
+		if _, ok := keyed.counts[key]; !ok {
+			keyed.seen = append(keyed.seen, key)
+		}
+		keyed.counts[key]++
+
+		accepted := newInfoTrace(fmt.Sprintf("%s ACCEPT (key %s)", matcherLabel(i, m, scope.formatter()), keyed.keyString(key, scope.formatter())))
+		if captured := captureMatched(m, message, scope); len(captured) > 0 {
+			accepted.Nested = append(accepted.Nested, newInfoTrace(fmt.Sprintf("Captured %s", captured)))
+		}
+
+		attempts = append(attempts, accepted)
+		return true, newInfoTrace(fmt.Sprintf("Combiner matched on matcher #%d", i), attempts...)
+	}
+
+	return false, newErrorTrace("Combiner failed match message", attempts...)
+}
+
+// TryMatch attempts to match the given message against the matchers contained
+// within the combiner, counting the message against its key. If a match is made, the
+// returned bool will be true. A TraceMessage is returned alongside (regardless of
+// the match being successful or not) which contains information about the messages handling.
+func (keyed *keyedCombiner[T, K]) TryMatch(message T) (bool, TraceMessage) {
+	return keyed.tryMatchCaptures(message, nil)
+}
+
+func (keyed *keyedCombiner[T, K]) tryMatchCaptures(message T, scope *captureScope) (bool, TraceMessage) {
+	ok, trace := keyed.tryMatch(message, scope)
+
+	mode := fmt.Sprintf("DISTINCT KEY %s mode with minimum of %d and maximum of %d", keyed.mode, keyed.min, keyed.max)
+	satisfiedTrace := keyed.updateSatisfied(scope.formatter())
+	saturatedTrace := keyed.updateSaturation(scope.formatter())
+
+	status := NewCombinerStatusTrace(mode, satisfiedTrace, saturatedTrace, keyed.keyCountsTrace(scope.formatter()))
+
+	trace.Nested = append(trace.Nested, status)
+	return ok, trace
+}
+
+// keyMax returns the maximum number of messages which may be matched for a single key.
+func (keyed *keyedCombiner[T, K]) keyMax() int {
+	if keyed.mode == modeSum {
+		return 1
+	}
+
+	return keyed.max
+}
+
+// keyMin returns the minimum number of messages which must be matched for a single (required) key.
+func (keyed *keyedCombiner[T, K]) keyMin() int {
+	if keyed.mode == modeSum {
+		return 1
+	}
+
+	return keyed.min
+}
+
+// formatKey formats the key taken from the message provided, redacting it if it
+// is the value of a redacted field of the message (as for captured values).
+func (keyed *keyedCombiner[T, K]) formatKey(message T, key K, values *valueFormatter) {
+	formatted := values.formatCaptured(reflect.ValueOf(message), key)
+	if _, ok := keyed.formatted[key]; !ok || formatted == redacted {
+		keyed.formatted[key] = formatted
+	}
+}
+
+// keyString returns the key provided as formatted when it was taken from a message, formatting
+// it using the formatter provided if it has not been seen (e.g. a key provided to WithKeys).
+func (keyed *keyedCombiner[T, K]) keyString(key K, values *valueFormatter) string {
+	if formatted, ok := keyed.formatted[key]; ok {
+		return formatted
+	}
+
+	return values.format(reflect.ValueOf(key), 0)
+}
+
+// keysString formats the keys provided in the same way as the %v verb formats a slice.
+func (keyed *keyedCombiner[T, K]) keysString(keys []K, values *valueFormatter) string {
+	formatted := make([]string, 0, len(keys))
+	for _, key := range keys {
+		formatted = append(formatted, keyed.keyString(key, values))
+	}
+
+	return "[" + strings.Join(formatted, " ") + "]"
+}
+
+func (keyed *keyedCombiner[T, K]) updateSatisfied(values *valueFormatter) TraceMessage {
+	//exhaustive:enforce
+	switch keyed.mode {
+	case modeEach:
+		missing := keyed.missingKeys()
+		if len(missing) > 0 {
+			keyed.satisfied = false
+			return newSatisfiedTrace(false, fmt.Sprintf("EACH key needs to match at least %d messages, but keys %s have not", keyed.min, keyed.keysString(missing, values)))
+		}
+
+		keyed.satisfied = len(keyed.seen) > 0
+		if keyed.satisfied {
+			return newSatisfiedTrace(true, fmt.Sprintf("EACH key has matched at least %d messages", keyed.min))
+		}
+
+		return newSatisfiedTrace(false, fmt.Sprintf("EACH key needs to match at least %d messages, but no keys have been seen", keyed.min))
+	case modeAny:
+		for _, key := range keyed.seen {
+			if keyed.counts[key] >= keyed.min {
+				keyed.satisfied = true
+				return newSatisfiedTrace(true, fmt.Sprintf("Key %s has matched against minimum messages (%d)", keyed.keyString(key, values), keyed.min))
+			}
+		}
+
+		keyed.satisfied = false
+		return newSatisfiedTrace(false, fmt.Sprintf("ANY key needs to match at least %d messages, but none have", keyed.min))
+	case modeSum:
+		distinct := len(keyed.seen)
+		keyed.satisfied = distinct >= keyed.min && distinct <= keyed.max
+		if keyed.satisfied {
+			return newSatisfiedTrace(true, fmt.Sprintf("Number of distinct keys (%d) has met minimum (%d)", distinct, keyed.min))
+		}
+
+		return newSatisfiedTrace(false, fmt.Sprintf("Number of distinct keys (%d) must meet %d", distinct, keyed.min))
+	}
+
+	panic("unreachable")
+}
+
+func (keyed *keyedCombiner[T, K]) updateSaturation(values *valueFormatter) TraceMessage {
+	//exhaustive:enforce
+	switch keyed.mode {
+	case modeEach:
+		if keyed.required == nil {
+			keyed.saturated = false
+			return newSaturatedTrace(false, "EACH key may match more messages, as any new key is accepted")
+		}
+
+		for _, key := range keyed.required {
+			if keyed.counts[key] < keyed.max {
+				keyed.saturated = false
+				return newSaturatedTrace(false, fmt.Sprintf("EACH required key needs to match %d messages, but key %s has not", keyed.max, keyed.keyString(key, values)))
+			}
+		}
+
+		keyed.saturated = true
+		return newSaturatedTrace(true, fmt.Sprintf("EACH required key has matched maximum allowed messages (%d)", keyed.max))
+	case modeAny:
+		for _, key := range keyed.seen {
+			if keyed.counts[key] >= keyed.max {
+				keyed.saturated = true
+				return newSaturatedTrace(true, fmt.Sprintf("Key %s has matched against maximum messages (%d)", keyed.keyString(key, values), keyed.max))
+			}
+		}
+
+		keyed.saturated = false
+		return newSaturatedTrace(false, fmt.Sprintf("ANY key needs to match %d messages, but none have", keyed.max))
+	case modeSum:
+		distinct := len(keyed.seen)
+		keyed.saturated = distinct >= keyed.max || (keyed.required != nil && distinct >= len(keyed.required))
+		if keyed.saturated {
+			return newSaturatedTrace(true, fmt.Sprintf("Number of distinct keys (%d) has met maximum", distinct))
+		}
+
+		return newSaturatedTrace(false, fmt.Sprintf("Number of distinct keys (%d) must be at least %d", distinct, keyed.max))
+	}
+
+	panic("unreachable")
+}
+
+// missingKeys returns the keys which have not yet matched the minimum number of
+// messages. If no keys are required, only the keys seen so far are considered.
+func (keyed *keyedCombiner[T, K]) missingKeys() []K {
+	keys := keyed.seen
+	if keyed.required != nil {
+		keys = keyed.required
+	}
+
+	missing := make([]K, 0)
+	for _, key := range keys {
+		if keyed.counts[key] < keyed.min {
+			missing = append(missing, key)
+		}
+	}
+
+	return missing
+}
+
+func (keyed *keyedCombiner[T, K]) isRequired(key K) bool {
+	for _, k := range keyed.required {
+		if k == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (keyed *keyedCombiner[T, K]) addDuplicate(key K) {
+	for _, k := range keyed.duplicates {
+		if k == key {
+			return
+		}
+	}
+
+	keyed.duplicates = append(keyed.duplicates, key)
+}
+
+func (keyed *keyedCombiner[T, K]) keyCountsTrace(values *valueFormatter) TraceMessage {
+	details := make([]TraceMessage, 0, len(keyed.seen)+2)
+	for _, key := range keyed.seen {
+		details = append(details, newInfoTrace(fmt.Sprintf("Key %s => %d message(s)", keyed.keyString(key, values), keyed.counts[key])))
+	}
+
+	if keyed.required != nil {
+		missing := make([]string, 0)
+		for _, key := range keyed.required {
+			if keyed.counts[key] < keyed.keyMin() {
+				missing = append(missing, keyed.keyString(key, values))
+			}
+		}
+
+		if len(missing) > 0 {
+			details = append(details, newInfoTrace(fmt.Sprintf("Missing keys: [%s]", strings.Join(missing, ", "))))
+		}
+	}
+
+	if len(keyed.duplicates) > 0 {
+		duplicates := make([]string, 0, len(keyed.duplicates))
+		for _, key := range keyed.duplicates {
+			duplicates = append(duplicates, keyed.keyString(key, values))
+		}
+
+		details = append(details, newInfoTrace(fmt.Sprintf("Duplicated keys: [%s]", strings.Join(duplicates, ", "))))
+	}
+
+	return NewTrace(LevelTrace, "Key counts", details...)
+}
+
+func (keyed *keyedCombiner[T, K]) report(values *valueFormatter) CombinerReport {
+	report := CombinerReport{
+		Description: keyed.summary(values),
+		Mode:        []string{"EACH KEY", "ANY KEY", "DISTINCT KEYS"}[keyed.mode],
+		Min:         keyed.min,
+		Max:         keyed.max,
+	}
+
+	for _, key := range keyed.seen {
+		report.Keys = append(report.Keys, KeyReport{Key: keyed.keyString(key, values), Count: keyed.counts[key]})
+	}
+
+	//exhaustive:enforce
+	switch keyed.mode {
+	case modeEach:
+		for _, key := range keyed.missingKeys() {
+			report.Shortfall = append(report.Shortfall, fmt.Sprintf("needs %d more for key %s", keyed.min-keyed.counts[key], keyed.keyString(key, values)))
+		}
+
+		if len(keyed.seen) == 0 && keyed.required == nil {
+			report.Shortfall = append(report.Shortfall, fmt.Sprintf("needs %d message(s) for at least one key", keyed.min))
+		}
+	case modeAny:
+		best := 0
+		for _, key := range keyed.seen {
+			best = max(best, keyed.counts[key])
+		}
+
+		report.Shortfall = append(report.Shortfall, fmt.Sprintf("needs %d more for any one key", keyed.min-best))
+	case modeSum:
+		report.Shortfall = append(report.Shortfall, fmt.Sprintf("needs %d more distinct key(s)", keyed.min-len(keyed.seen)))
+	}
+
+	return report
+}
+
+// Reset discards all messages matched by this combiner, allowing it to be used by another
+// layer once the expecter using it has finished. The keys provided to WithKeys are retained.
+func (keyed *keyedCombiner[T, K]) Reset() {
+	keyed.counts = make(map[K]int)
+	keyed.seen = nil
+	keyed.duplicates = nil
+	keyed.formatted = make(map[K]string)
+	keyed.ResetBase()
+}
+
+// Describe describes the bounds of this combiner, the keys
+// it requires (if any), and each of its matchers.
+func (keyed *keyedCombiner[T, K]) Describe() string {
+	return keyed.describeWith(defaultValueFormatter)
+}
+
+func (keyed *keyedCombiner[T, K]) describeWith(values *valueFormatter) string {
+	return describeList(keyed.summary(values), describeMatchers(keyed.matchers, values))
+}
+
+func (keyed *keyedCombiner[T, K]) describeTree(values *valueFormatter) TraceMessage {
+	return newInfoTrace(keyed.summary(values), describeMatcherTree(keyed.matchers, values)...)
+}
+
+func (keyed *keyedCombiner[T, K]) summary(values *valueFormatter) string {
+	bounds := describeBounds(keyed.min, keyed.max)
+
+	keys := ""
+	if keyed.required != nil {
+		keys = " in " + keyed.keysString(keyed.required, values)
+	}
+
+	//exhaustive:enforce
+	switch keyed.mode {
+	case modeEach:
+		return fmt.Sprintf("%s message(s) for each key%s, matching any of", bounds, keys)
+	case modeAny:
+		return fmt.Sprintf("%s message(s) for any one key%s, matching any of", bounds, keys)
+	case modeSum:
+		return fmt.Sprintf("%s distinct key(s)%s, matching any of", bounds, keys)
+	}
+
+	panic("unreachable")
+}
@@ -0,0 +1,210 @@
+package chanassert_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hbomb79/go-chanassert"
+)
+
+// workerKey returns the worker ID from messages of the form "<worker>:<event>".
+func workerKey(message string) string {
+	return strings.SplitN(message, ":", 2)[0]
+}
+
+func Test_AtLeastNDistinct(t *testing.T) {
+	makeCombiner := func() chanassert.Combiner[string] {
+		return chanassert.AtLeastNDistinct(3, workerKey, chanassert.MatchStringContains(":ready"))
+	}
+
+	tests := []combinerTest[string]{
+		{
+			summary:   "Distinct keys",
+			messages:  []string{"w1:ready", "w2:ready", "w3:ready", "w4:ready"},
+			expected:  []bool{true, true, true, true},
+			satisfied: []bool{false, false, true, true},
+		},
+		{
+			summary:   "Duplicate keys",
+			messages:  []string{"w1:ready", "w1:ready", "w2:ready", "w2:ready", "w3:ready"},
+			expected:  []bool{true, false, true, false, true},
+			satisfied: []bool{false, false, false, false, true},
+		},
+		{
+			summary:   "Non-matching messages",
+			messages:  []string{"w1:ready", "w2:stopped", "w2:ready", "w3:ready"},
+			expected:  []bool{true, false, true, true},
+			satisfied: []bool{false, false, false, true},
+		},
+	}
+
+	runCombinerTests(t, makeCombiner, tests)
+}
+
+func Test_BetweenNDistinct(t *testing.T) {
+	makeCombiner := func() chanassert.Combiner[string] {
+		return chanassert.BetweenNDistinct(1, 2, workerKey, chanassert.MatchStringContains(":ready"))
+	}
+
+	tests := []combinerTest[string]{
+		{
+			summary:   "Maximum distinct keys",
+			messages:  []string{"w1:ready", "w2:ready", "w3:ready"},
+			expected:  []bool{true, true, false},
+			satisfied: []bool{true, true, true},
+		},
+	}
+
+	runCombinerTests(t, makeCombiner, tests)
+}
+
+func Test_ExactlyNOfEachKey(t *testing.T) {
+	makeCombiner := func() chanassert.Combiner[string] {
+		return chanassert.ExactlyNOfEachKey(1, workerKey, chanassert.MatchStringContains(":checkpoint")).
+			WithKeys("s0", "s1", "s2")
+	}
+
+	tests := []combinerTest[string]{
+		{
+			summary:   "Every key once",
+			messages:  []string{"s1:checkpoint", "s0:checkpoint", "s2:checkpoint"},
+			expected:  []bool{true, true, true},
+			satisfied: []bool{false, false, true},
+		},
+		{
+			summary:   "Duplicate key",
+			messages:  []string{"s1:checkpoint", "s1:checkpoint", "s0:checkpoint", "s2:checkpoint"},
+			expected:  []bool{true, false, true, true},
+			satisfied: []bool{false, false, false, true},
+		},
+		{
+			summary:   "Unknown key",
+			messages:  []string{"s1:checkpoint", "s3:checkpoint", "s0:checkpoint"},
+			expected:  []bool{true, false, true},
+			satisfied: []bool{false, false, false},
+		},
+		{
+			summary:   "Saturated once all keys matched",
+			messages:  []string{"s0:checkpoint", "s1:checkpoint", "s2:checkpoint", "s0:checkpoint"},
+			expected:  []bool{true, true, true, false},
+			satisfied: []bool{false, false, true, true},
+		},
+	}
+
+	runCombinerTests(t, makeCombiner, tests)
+}
+
+func Test_AtLeastNOfEachKey(t *testing.T) {
+	makeCombiner := func() chanassert.Combiner[string] {
+		return chanassert.AtLeastNOfEachKey(2, workerKey, chanassert.MatchStringContains(":ping"))
+	}
+
+	tests := []combinerTest[string]{
+		{
+			summary:   "Each key seen N times",
+			messages:  []string{"w1:ping", "w2:ping", "w1:ping", "w2:ping", "w2:ping"},
+			expected:  []bool{true, true, true, true, true},
+			satisfied: []bool{false, false, false, true, true},
+		},
+		{
+			summary:   "New key unsatisfies combiner",
+			messages:  []string{"w1:ping", "w1:ping", "w2:ping"},
+			expected:  []bool{true, true, true},
+			satisfied: []bool{false, true, false},
+		},
+	}
+
+	runCombinerTests(t, makeCombiner, tests)
+}
+
+func Test_ExactlyNOfAnyKey(t *testing.T) {
+	makeCombiner := func() chanassert.Combiner[string] {
+		return chanassert.ExactlyNOfAnyKey(2, workerKey, chanassert.MatchStringContains(":ping"))
+	}
+
+	tests := []combinerTest[string]{
+		{
+			summary:   "One key seen N times",
+			messages:  []string{"w1:ping", "w2:ping", "w2:ping", "w1:ping"},
+			expected:  []bool{true, true, true, false},
+			satisfied: []bool{false, false, true, true},
+		},
+	}
+
+	runCombinerTests(t, makeCombiner, tests)
+}
+
+func Test_BetweenNOfAnyKey(t *testing.T) {
+	makeCombiner := func() chanassert.Combiner[string] {
+		return chanassert.BetweenNOfAnyKey(1, 3, workerKey, chanassert.MatchStringContains(":ping"))
+	}
+
+	tests := []combinerTest[string]{
+		{
+			summary:   "Saturated once any key reaches maximum",
+			messages:  []string{"w1:ping", "w1:ping", "w2:ping", "w1:ping", "w2:ping"},
+			expected:  []bool{true, true, true, true, false},
+			satisfied: []bool{true, true, true, true, true},
+		},
+	}
+
+	runCombinerTests(t, makeCombiner, tests)
+}
+
+func Test_AtLeastNOfAnyKey(t *testing.T) {
+	makeCombiner := func() chanassert.Combiner[string] {
+		return chanassert.AtLeastNOfAnyKey(2, workerKey, chanassert.MatchStringContains(":ping"))
+	}
+
+	tests := []combinerTest[string]{
+		{
+			summary:   "One key seen at least N times",
+			messages:  []string{"w1:ping", "w2:ping", "w2:ping", "w2:ping"},
+			expected:  []bool{true, true, true, true},
+			satisfied: []bool{false, false, true, true},
+		},
+	}
+
+	runCombinerTests(t, makeCombiner, tests)
+}
+
+func Test_KeyedCombiner_Trace_MissingKeys(t *testing.T) {
+	combiner := chanassert.ExactlyNOfEachKey(2, workerKey, chanassert.MatchStringContains(":checkpoint")).
+		WithKeys("s0", "s1", "s2")
+
+	var trace chanassert.TraceMessage
+	for _, msg := range []string{"s1:checkpoint", "s1:checkpoint", "s2:checkpoint"} {
+		_, trace = combiner.TryMatch(msg)
+	}
+
+	// s2 has been seen, but has not yet matched the minimum of 2 messages
+	builder := &strings.Builder{}
+	trace.PrintTrace(builder, true, 0)
+	if expected := "Missing keys: [s0, s2]"; !strings.Contains(builder.String(), expected) {
+		t.Errorf("expected trace to contain %q, but it did not:\n%s", expected, builder.String())
+	}
+}
+
+func Test_KeyedCombiner_Trace(t *testing.T) {
+	combiner := chanassert.BetweenNOfEachKey(1, 1, workerKey, chanassert.MatchStringContains(":checkpoint")).
+		WithKeys("s0", "s1", "s2")
+
+	var trace chanassert.TraceMessage
+	for _, msg := range []string{"s1:checkpoint", "s1:checkpoint", "s2:checkpoint"} {
+		_, trace = combiner.TryMatch(msg)
+	}
+
+	builder := &strings.Builder{}
+	trace.PrintTrace(builder, true, 0)
+	for _, expected := range []string{
+		"Matcher #0 (contains \":checkpoint\") ACCEPT (key s2)",
+		"EACH key needs to match at least 1 messages, but keys [s0] have not",
+		"Key s1 => 1 message(s)",
+		"Missing keys: [s0]",
+		"Duplicated keys: [s1]",
+	} {
+		if !strings.Contains(builder.String(), expected) {
+			t.Errorf("expected trace to contain %q, but it did not:\n%s", expected, builder.String())
+		}
+	}
+}
@@ -6,15 +6,39 @@ import (
 	"time"
 )
 
+// LayerMode determines how a layer uses the satisfaction
+// of its combiners to decide if it is satisfied.
 type LayerMode int
 
 const (
-	and LayerMode = iota
-	or
+	// ModeAnd layers are satisfied once ALL combiners are satisfied.
+	ModeAnd LayerMode = iota
+	// ModeOr layers are satisfied once ANY combiner is satisfied.
+	ModeOr
+	// ModeQuorum layers are satisfied once AT LEAST K combiners are satisfied.
+	ModeQuorum
+	// ModeExclusive layers deliver messages ONLY to the first combiner to
+	// accept a message, and are satisfied once that combiner is satisfied.
+	ModeExclusive
 )
 
 func (mode LayerMode) String() string {
-	return []string{"AND", "OR"}[mode]
+	return []string{"AND", "OR", "QUORUM", "EXCLUSIVE"}[mode]
+}
+
+func (mode LayerMode) MarshalText() ([]byte, error) {
+	return []byte(mode.String()), nil
+}
+
+func (mode *LayerMode) UnmarshalText(text []byte) error {
+	for _, m := range []LayerMode{ModeAnd, ModeOr, ModeQuorum, ModeExclusive} {
+		if m.String() == string(text) {
+			*mode = m
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown layer mode %q", text)
 }
 
 type layer[T any] struct {
@@ -24,8 +48,24 @@ type layer[T any] struct {
 	mode     LayerMode
 	layerIdx int
 
+	// name is the name given to the layer by the user, if any (see [Expecter.Named]).
+	name string
+
+	// quorum is the number of combiners which must be
+	// satisfied for a layer in 'Quorum' mode.
+	quorum int
+
+	// chosenIdx is the index of the combiner which a layer in 'Exclusive'
+	// mode has committed to, or -1 if no combiner has been chosen yet.
+	chosenIdx int
+
 	timeout   *time.Duration
 	startTime *time.Time
+
+	captures *captureScope
+
+	// format formats messages for the trace (see [Expecter.FormatMessages]).
+	format func(T) string
 }
 
 func (layer *layer[T]) Begin() {
@@ -46,96 +86,314 @@ func (layer *layer[T]) TryMatch(message T) (bool, TraceMessage) {
 
 func (layer *layer[T]) tryMatch(message T) (bool, TraceMessage) {
 	if layer.timeoutElapsed() {
-		return false, newInfoTrace(fmt.Sprintf("Message %v (%T) REJECTED, timeout of layer (%s) has been reached", message, message, layer.timeout))
+		return false, newErrorTrace(fmt.Sprintf("Message %s (%T) REJECTED, timeout of layer (%s) has been reached", layer.format(message), message, layer.timeout))
 	}
 
 	defer layer.updateSatisfied()
 
 	traces := make([]TraceMessage, 0)
 	for idx, combiner := range layer.combiners {
-		ok, trace := combiner.TryMatch(message)
-		trace.Message = fmt.Sprintf("Combiner #%d: ", idx) + trace.Message
+		exclusive := layer.mode == ModeExclusive && !isOptional(combiner)
+		if exclusive && layer.chosenIdx != -1 && layer.chosenIdx != idx {
+			traces = append(traces, newErrorTrace(fmt.Sprintf("%s: skipped, layer is exclusive to %s", label("Combiner", idx, combiner), layer.chosenLabel())))
+			continue
+		}
+
+		ok, trace := tryMatchCombiner(combiner, message, layer.captures)
+		trace.Message = label("Combiner", idx, combiner) + ": " + trace.Message
 
 		traces = append(traces, trace)
 		if ok {
-			return true, newInfoTrace(fmt.Sprintf("Layer #%d matched message against combiner #%d", layer.layerIdx, idx), traces...)
+			if exclusive && layer.chosenIdx == -1 {
+				layer.chosenIdx = idx
+				traces = append(traces, newInfoTrace("Layer is now exclusive to "+layer.chosenLabel()))
+			}
+
+			return true, newInfoTrace(fmt.Sprintf("%s matched message against %s", label("Layer", layer.layerIdx, layer), label("combiner", idx, combiner)), traces...)
 		}
 	}
 
-	return false, newInfoTrace(fmt.Sprintf("Layer #%d could not match message against any combiners", layer.layerIdx), traces...)
+	return false, newErrorTrace(fmt.Sprintf("%s could not match message against any combiners", label("Layer", layer.layerIdx, layer)), traces...)
+}
+
+// chosenLabel returns the label of the combiner which a layer
+// in 'Exclusive' mode has committed to.
+func (layer *layer[T]) chosenLabel() string {
+	return label("combiner", layer.chosenIdx, layer.combiners[layer.chosenIdx])
+}
+
+// Finish performs no final validation for this layer, as
+// any failure to become satisfied is reported by the expecter.
+func (layer *layer[T]) Finish() error {
+	return nil
 }
 
 func (layer *layer[T]) IsSatisfied() bool {
 	return layer.satisfied
 }
 
+// Name returns the name given to this layer, or an empty string if it has not been named.
+func (layer *layer[T]) Name() string {
+	return layer.name
+}
+
+// Describe describes how this layer becomes satisfied, and each of its combiners.
+func (layer *layer[T]) Describe() string {
+	return layer.describeWith(defaultValueFormatter)
+}
+
+func (layer *layer[T]) describeWith(values *valueFormatter) string {
+	return describeList(layer.summary(), describeCombiners(layer.combiners, values))
+}
+
+func (layer *layer[T]) describeTree(values *valueFormatter) TraceMessage {
+	return newInfoTrace(layer.summary(), describeCombinerTree(layer.combiners, values)...)
+}
+
+func (layer *layer[T]) summary() string {
+	var summary string
+	//exhaustive:enforce
+	switch layer.mode {
+	case ModeAnd:
+		summary = "ALL combiners must be satisfied"
+	case ModeOr:
+		summary = "ANY combiner must be satisfied"
+	case ModeQuorum:
+		summary = fmt.Sprintf("AT LEAST %d combiners must be satisfied", layer.quorum)
+	case ModeExclusive:
+		summary = "the FIRST combiner to accept a message must be satisfied"
+	}
+
+	if layer.timeout != nil {
+		return fmt.Sprintf("%s (%q mode, within %s)", summary, layer.mode, layer.timeout)
+	}
+
+	return fmt.Sprintf("%s (%q mode)", summary, layer.mode)
+}
+
+func (layer *layer[T]) report(values *valueFormatter) LayerReport {
+	report := LayerReport{
+		Description: layer.summary(),
+		Mode:        layer.mode,
+		Quorum:      layer.quorum,
+		Combiners:   reportCombiners(layer.combiners, values),
+	}
+
+	if layer.satisfied {
+		return report
+	}
+
+	//exhaustive:enforce
+	switch layer.mode {
+	case ModeAnd:
+		report.Shortfall = unsatisfiedCombiners(layer.combiners)
+	case ModeOr:
+		report.Shortfall = []string{"needs any one combiner to be satisfied"}
+	case ModeQuorum:
+		satisfied, _, _ := partitionCombiners(layer.combiners)
+		report.Shortfall = []string{fmt.Sprintf("needs %d more combiner(s) to be satisfied", layer.quorum-len(satisfied))}
+	case ModeExclusive:
+		if layer.chosenIdx == -1 {
+			report.Shortfall = []string{"needs any one combiner to accept a message"}
+		} else {
+			report.Shortfall = []string{fmt.Sprintf("needs chosen %s to be satisfied", layer.chosenLabel())}
+		}
+	}
+
+	return report
+}
+
 func (layer *layer[T]) updateSatisfied() {
+	// Optional combiners are neutral; they never hold a layer
+	// open, and never cause a layer to become satisfied
+	satisfied, notSatisfied, _ := partitionCombiners(layer.combiners)
+
 	//exhaustive:enforce
 	switch layer.mode {
-	case and:
+	case ModeAnd:
 		// In 'And' mode, the layer becomes satisfied once all
 		// combiners are satisfied
-		for _, combiner := range layer.combiners {
-			if !combiner.IsSatisfied() {
-				layer.satisfied = false
-				return
-			}
-		}
-
-		layer.satisfied = true
-	case or:
+		layer.satisfied = len(notSatisfied) == 0
+	case ModeOr:
 		// In 'Or' mode, the layer becomes satisfied any combiner
 		// is satisfied
-		for _, combiner := range layer.combiners {
-			if combiner.IsSatisfied() {
-				layer.satisfied = true
-				return
-			}
-		}
-
-		layer.satisfied = false
+		layer.satisfied = len(satisfied) > 0
+	case ModeQuorum:
+		// In 'Quorum' mode, the layer becomes satisfied once
+		// at least 'quorum' combiners are satisfied
+		layer.satisfied = len(satisfied) >= layer.quorum
+	case ModeExclusive:
+		// In 'Exclusive' mode, the layer becomes satisfied once
+		// the chosen combiner is satisfied
+		layer.satisfied = layer.chosenIdx != -1 && layer.combiners[layer.chosenIdx].IsSatisfied()
 	}
 }
 
+func (layer *layer[T]) layerTimeout() *time.Duration {
+	return layer.timeout
+}
+
 func (layer *layer[T]) timeoutElapsed() bool {
 	return layer.timeout != nil && time.Until(layer.startTime.Add(*layer.timeout)) <= 0
 }
 
 func (layer *layer[T]) makeLayerStatusTrace() TraceMessage {
-	return newDebugTrace("Layer Status",
+	return NewLayerStatusTrace(
 		newInfoTrace(fmt.Sprintf("%q mode", layer.mode)),
 		layer.makeCombinersTrace(),
 	)
 }
 
 func (layer *layer[T]) makeCombinersTrace() TraceMessage {
-	notSatisfied := make(idxList, 0)
-	satisfied := make(idxList, 0)
-	for idx, combiner := range layer.combiners {
-		if combiner.IsSatisfied() {
-			satisfied = append(satisfied, idx)
-		} else {
-			notSatisfied = append(notSatisfied, idx)
-		}
+	return NewCombinersStatusTrace(layer.mode, layer.combiners, WithQuorum(layer.quorum), WithChosen(layer.chosenIdx))
+}
+
+// NewLayerStatusTrace returns the 'Layer Status' debug trace which the built-in layers
+// attach to the trace of every message they process, containing the status traces provided.
+func NewLayerStatusTrace(status ...TraceMessage) TraceMessage {
+	return newDebugTrace("Layer Status", status...)
+}
+
+type combinersStatusConfig struct {
+	quorum    int
+	chosenIdx int
+}
+
+// CombinersStatusOption provides the state of a layer which is required by
+// [NewCombinersStatusTrace] for the [ModeQuorum] and [ModeExclusive] modes.
+type CombinersStatusOption func(*combinersStatusConfig)
+
+// WithQuorum provides the number of combiners which must be satisfied by a layer in [ModeQuorum].
+func WithQuorum(k int) CombinersStatusOption {
+	return func(config *combinersStatusConfig) {
+		config.quorum = k
 	}
+}
 
-	if layer.mode == and {
+// WithChosen provides the index of the combiner chosen by a layer in [ModeExclusive],
+// or -1 if no combiner has been chosen yet (the default).
+func WithChosen(idx int) CombinersStatusOption {
+	return func(config *combinersStatusConfig) {
+		config.chosenIdx = idx
+	}
+}
+
+// NewCombinersStatusTrace returns a trace which summarises which of the combiners provided
+// are satisfied, and whether this satisfies a layer using the given mode. The quorum of a layer
+// in [ModeQuorum] must be provided using [WithQuorum], and the combiner chosen by a layer in
+// [ModeExclusive] (if any) using [WithChosen]. This function will panic if the quorum of a
+// layer in [ModeQuorum] is not provided, or if the chosen combiner is out of range.
+func NewCombinersStatusTrace[T any](mode LayerMode, combiners []Combiner[T], opts ...CombinersStatusOption) TraceMessage {
+	config := combinersStatusConfig{chosenIdx: -1}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	satisfied, notSatisfied, optional := partitionCombiners(combiners)
+	required := len(satisfied) + len(notSatisfied)
+	suffix := optionalSuffix(optional)
+
+	//exhaustive:enforce
+	switch mode {
+	case ModeAnd:
 		switch {
-		case len(notSatisfied) == len(layer.combiners):
-			return newInfoTrace(fmt.Sprintf("NOT satisfied: no combiners satisfied (of %d)", len(layer.combiners)))
-		case len(satisfied) == len(layer.combiners):
-			return newInfoTrace(fmt.Sprintf("SATISFIED: all combiners satisfied (%d)", len(layer.combiners)))
+		case len(notSatisfied) == required && required > 0:
+			return newInfoTrace(fmt.Sprintf("NOT satisfied: no combiners satisfied (of %d)%s", required, suffix))
+		case len(satisfied) == required:
+			return newInfoTrace(fmt.Sprintf("SATISFIED: all combiners satisfied (%d)%s", required, suffix))
 		default:
-			return newInfoTrace(fmt.Sprintf("NOT satisfied: only combiners %s satisfied, %s NOT yet satisfied", satisfied, notSatisfied))
+			return newInfoTrace(fmt.Sprintf("NOT satisfied: only combiners %s satisfied, %s NOT yet satisfied%s", satisfied, notSatisfied, suffix))
 		}
-	} else {
+	case ModeOr:
 		switch {
 		case len(satisfied) == 0:
-			return newInfoTrace(fmt.Sprintf("NOT satisfied: no combiners satisfied (of %d)", len(layer.combiners)))
+			return newInfoTrace(fmt.Sprintf("NOT satisfied: no combiners satisfied (of %d)%s", required, suffix))
 		default:
-			return newInfoTrace(fmt.Sprintf("SATISFIED: combiners %s satisfied (and %s NOT satisfied, but 'OR' mode only needs ONE combiner to be satisfied)", satisfied, notSatisfied))
+			return newInfoTrace(fmt.Sprintf("SATISFIED: combiners %s satisfied (and %s NOT satisfied, but 'OR' mode only needs ONE combiner to be satisfied)%s", satisfied, notSatisfied, suffix))
+		}
+	case ModeQuorum:
+		if config.quorum < 1 {
+			panic("NewCombinersStatusTrace requires the quorum of a 'QUORUM' mode layer (see WithQuorum)")
 		}
+
+		if len(satisfied) >= config.quorum {
+			return newInfoTrace(fmt.Sprintf("SATISFIED: combiners %s satisfied ('QUORUM' mode needs %d of %d combiners to be satisfied)%s", satisfied, config.quorum, required, suffix))
+		}
+
+		return newInfoTrace(fmt.Sprintf("NOT satisfied: combiners %s satisfied, but 'QUORUM' mode needs %d of %d combiners to be satisfied%s", satisfied, config.quorum, required, suffix))
+	case ModeExclusive:
+		if config.chosenIdx == -1 {
+			return newInfoTrace(fmt.Sprintf("NOT satisfied: no combiner chosen yet (of %d)", len(combiners)))
+		}
+
+		chosen := combiners[config.chosenIdx]
+		if chosen.IsSatisfied() {
+			return newInfoTrace(fmt.Sprintf("SATISFIED: chosen %s satisfied", label("combiner", config.chosenIdx, chosen)))
+		}
+
+		return newInfoTrace(fmt.Sprintf("NOT satisfied: chosen %s NOT yet satisfied", label("combiner", config.chosenIdx, chosen)))
 	}
+
+	panic(fmt.Sprintf("unknown layer mode %d", mode))
+}
+
+// partitionCombiners splits the indexes of the combiners provided in to those which are
+// satisfied, those which are not, and those which are optional (see [MayOccur]).
+func partitionCombiners[T any](combiners []Combiner[T]) (idxList, idxList, idxList) {
+	satisfied := make(idxList, 0)
+	notSatisfied := make(idxList, 0)
+	optional := make(idxList, 0)
+	for idx, combiner := range combiners {
+		switch {
+		case isOptional(combiner):
+			optional = append(optional, idx)
+		case combiner.IsSatisfied():
+			satisfied = append(satisfied, idx)
+		default:
+			notSatisfied = append(notSatisfied, idx)
+		}
+	}
+
+	return satisfied, notSatisfied, optional
+}
+
+func optionalSuffix(optional idxList) string {
+	if len(optional) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(" (optional combiners %s ignored)", optional)
+}
+
+// optionalMarker is implemented by combiners which can be optional. Optional
+// combiners are neutral to the layer which holds them: they never hold the layer
+// open, and never cause the layer to become satisfied.
+type optionalMarker interface {
+	isOptional() bool
+}
+
+func isOptional[T any](combiner Combiner[T]) bool {
+	if c, ok := combiner.(optionalMarker); ok {
+		return c.isOptional()
+	}
+
+	return false
+}
+
+// saturatingCombiner is implemented by combiners which are able to report
+// that they cannot accept any further messages (see [Combiner]).
+type saturatingCombiner interface {
+	IsSaturated() bool
+}
+
+// isSaturated returns whether the combiner is saturated. Combiners
+// which do not report their saturation are never saturated.
+func isSaturated[T any](combiner Combiner[T]) bool {
+	if c, ok := combiner.(saturatingCombiner); ok {
+		return c.IsSaturated()
+	}
+
+	return false
 }
 
 // idxList is a simple wrapper around a list of ints which
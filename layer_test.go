@@ -1,6 +1,8 @@
 package chanassert_test
 
 import (
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -510,3 +512,217 @@ func Test_Expect_OneOf_Struct(t *testing.T) {
 		})
 	}
 }
+
+// firstWinsLayer is a custom layer which delivers messages to the
+// combiner which accepted the first message ONLY. It becomes satisfied
+// once that combiner is satisfied.
+type firstWinsLayer struct {
+	combiners []chanassert.Combiner[string]
+	chosen    chanassert.Combiner[string]
+	begun     bool
+	finished  int
+	finishErr error
+}
+
+func (l *firstWinsLayer) Begin() { l.begun = true }
+
+func (l *firstWinsLayer) TryMatch(message string) (bool, chanassert.TraceMessage) {
+	status := chanassert.NewLayerStatusTrace(chanassert.NewCombinersStatusTrace(chanassert.ModeOr, l.combiners))
+	if l.chosen != nil {
+		ok, trace := l.chosen.TryMatch(message)
+		return ok, chanassert.NewInfoTrace("First wins layer delivered message to chosen combiner", trace, status)
+	}
+
+	for _, c := range l.combiners {
+		if ok, trace := c.TryMatch(message); ok {
+			l.chosen = c
+			return true, chanassert.NewInfoTrace("First wins layer chose combiner", trace, status)
+		}
+	}
+
+	return false, chanassert.NewInfoTrace("First wins layer could not match message", status)
+}
+
+func (l *firstWinsLayer) IsSatisfied() bool { return l.chosen != nil && l.chosen.IsSatisfied() }
+
+func (l *firstWinsLayer) Finish() error {
+	l.finished++
+	return l.finishErr
+}
+
+func Test_ExpectLayer(t *testing.T) {
+	errFinish := errors.New("final validation failed")
+
+	makeExpecter := func(finishErr error) (chan string, *firstWinsLayer, chanassert.Expecter[string]) {
+		ch := make(chan string, 10)
+		custom := &firstWinsLayer{
+			combiners: []chanassert.Combiner[string]{
+				chanassert.AllOf(chanassert.MatchEqual("a1"), chanassert.MatchEqual("a2")),
+				chanassert.AllOf(chanassert.MatchEqual("b1"), chanassert.MatchEqual("b2")),
+			},
+			finishErr: finishErr,
+		}
+
+		return ch, custom, chanassert.NewChannelExpecter(ch).
+			ExpectLayer(custom).
+			Expect(chanassert.OneOf(chanassert.MatchEqual("done")))
+	}
+
+	t.Run("Custom layer satisfied", func(t *testing.T) {
+		t.Parallel()
+		ch, custom, exp := makeExpecter(nil)
+		exp.Listen()
+		for _, msg := range []string{"b1", "b2", "done"} {
+			ch <- msg
+		}
+
+		exp.AssertSatisfied(t, time.Second)
+		if !custom.begun {
+			t.Errorf("expected custom layer to have begun")
+		}
+
+		builder := &strings.Builder{}
+		for _, res := range exp.ProcessedMessages() {
+			res.PrettyPrint(builder, true)
+		}
+		if !strings.Contains(builder.String(), "[DEBUG] Layer Status") {
+			t.Errorf("expected custom layer trace to contain layer status, but got:\n%s", builder.String())
+		}
+	})
+
+	t.Run("Custom layer rejects other path", func(t *testing.T) {
+		t.Parallel()
+		ch, _, exp := makeExpecter(nil)
+		exp.Listen()
+		for _, msg := range []string{"a1", "b1", "a2", "done"} {
+			ch <- msg
+		}
+
+		errs := exp.AwaitSatisfied(time.Second)
+		assertErrorsExpected[string](t, errs, expectedErrors{rejectedError})
+	})
+
+	t.Run("Custom layer fails final validation", func(t *testing.T) {
+		t.Parallel()
+		ch, _, exp := makeExpecter(errFinish)
+		exp.Listen()
+		for _, msg := range []string{"a1", "a2", "done"} {
+			ch <- msg
+		}
+
+		errs := exp.AwaitSatisfied(time.Second)
+		if len(errs) != 1 {
+			t.Fatalf("expected exactly one error, got %s", errs)
+		}
+
+		var layerErr chanassert.LayerError
+		if !errors.As(errs[0], &layerErr) || layerErr.LayerIdx != 0 {
+			t.Errorf("expected LayerError for layer #0, got %v", errs[0])
+		}
+		if !errors.Is(errs[0], errFinish) {
+			t.Errorf("expected LayerError to wrap the error returned by Finish, got %v", errs[0])
+		}
+	})
+
+	t.Run("Finish called once", func(t *testing.T) {
+		t.Parallel()
+		ch, custom, exp := makeExpecter(errFinish)
+		exp.Listen()
+		for _, msg := range []string{"a1", "a2", "done"} {
+			ch <- msg
+		}
+
+		for call := 1; call <= 2; call++ {
+			errs := exp.AwaitSatisfied(time.Second)
+			if len(errs) != 1 || !errors.Is(errs[0], errFinish) {
+				t.Errorf("expected call #%d to AwaitSatisfied to report the LayerError, got %s", call, errs)
+			}
+		}
+
+		if custom.finished != 1 {
+			t.Errorf("expected Finish to be called once, but it was called %d times", custom.finished)
+		}
+	})
+
+	t.Run("Finish not called without Listen", func(t *testing.T) {
+		t.Parallel()
+		_, custom, exp := makeExpecter(errFinish)
+		exp.AwaitSatisfied(time.Millisecond * 10)
+
+		if custom.begun || custom.finished != 0 {
+			t.Errorf("expected layer which never began not to be finished (finished %d times)", custom.finished)
+		}
+	})
+
+	t.Run("Finish not called for unselected layers", func(t *testing.T) {
+		t.Parallel()
+		ch := make(chan string, 10)
+		unselected := &firstWinsLayer{finishErr: errFinish}
+		exp := chanassert.NewChannelExpecter(ch).
+			Expect(chanassert.OneOf(chanassert.MatchEqual("first"))).
+			ExpectLayer(unselected)
+		exp.Listen()
+
+		errs := exp.AwaitSatisfied(time.Millisecond * 100)
+		assertErrorsExpected[string](t, errs, expectedErrors{terminatedError, unsatisfiedError})
+		if unselected.begun {
+			t.Errorf("expected unselected custom layer to never begin")
+		}
+	})
+}
+
+func Test_NewCombinersStatusTrace(t *testing.T) {
+	satisfied := chanassert.OneOf(chanassert.MatchEqual("a"))
+	satisfied.TryMatch("a")
+	combiners := []chanassert.Combiner[string]{
+		satisfied,
+		chanassert.OneOf(chanassert.MatchEqual("b")),
+		chanassert.OneOf(chanassert.MatchEqual("c")),
+	}
+
+	tests := []struct {
+		summary  string
+		trace    chanassert.TraceMessage
+		expected string
+	}{
+		{
+			summary:  "Quorum reached",
+			trace:    chanassert.NewCombinersStatusTrace(chanassert.ModeQuorum, combiners, chanassert.WithQuorum(1)),
+			expected: "SATISFIED: combiners [#0] satisfied ('QUORUM' mode needs 1 of 3 combiners to be satisfied)",
+		},
+		{
+			summary:  "Quorum not reached",
+			trace:    chanassert.NewCombinersStatusTrace(chanassert.ModeQuorum, combiners, chanassert.WithQuorum(2)),
+			expected: "NOT satisfied: combiners [#0] satisfied, but 'QUORUM' mode needs 2 of 3 combiners to be satisfied",
+		},
+		{
+			summary:  "Exclusive without chosen combiner",
+			trace:    chanassert.NewCombinersStatusTrace(chanassert.ModeExclusive, combiners),
+			expected: "NOT satisfied: no combiner chosen yet (of 3)",
+		},
+		{
+			summary:  "Exclusive with satisfied combiner",
+			trace:    chanassert.NewCombinersStatusTrace(chanassert.ModeExclusive, combiners, chanassert.WithChosen(0)),
+			expected: "SATISFIED: chosen combiner #0 satisfied",
+		},
+		{
+			summary:  "Exclusive with unsatisfied combiner",
+			trace:    chanassert.NewCombinersStatusTrace(chanassert.ModeExclusive, combiners, chanassert.WithChosen(1)),
+			expected: "NOT satisfied: chosen combiner #1 NOT yet satisfied",
+		},
+	}
+
+	for _, test := range tests {
+		if test.trace.Message != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.summary, test.expected, test.trace.Message)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected NewCombinersStatusTrace to panic without the quorum of a 'QUORUM' mode layer")
+		}
+	}()
+
+	chanassert.NewCombinersStatusTrace(chanassert.ModeQuorum, combiners)
+}
@@ -3,6 +3,7 @@ package chanassert
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 )
 
@@ -13,6 +14,21 @@ type Matcher[T any] interface {
 	DoesMatch(t T) bool
 }
 
+// ExplainingMatcher is an optional interface which may be implemented by a [Matcher] to
+// describe why a message did not match. The description is included in the trace for the
+// message, in place of the generic "no match" (e.g. "Matcher #0 REJECT: field Status: expected 200, got 404").
+//
+// The built-in reflection matchers ([MatchStruct], [MatchStructFields] and [MatchStructPartial])
+// implement this interface, describing each field which differed.
+type ExplainingMatcher[T any] interface {
+	Matcher[T]
+
+	// ExplainMismatch is called when DoesMatch has returned false for
+	// the message, and must return a description of why the message did
+	// not match. If an empty string is returned, "no match" is used instead.
+	ExplainMismatch(t T) string
+}
+
 // MatchEqual returns a matcher which will check if the
 // value provided is equal to the messages provided.
 func MatchEqual[T comparable](val T) *equalMatcher[T] {
@@ -45,6 +61,37 @@ func MatchPredicate[T any](predicate func(T) bool) *predicateMatcher[T] {
 	return &predicateMatcher[T]{predicate: predicate}
 }
 
+// MatchNot returns a matcher which matches messages which are
+// NOT matched by the matcher provided.
+func MatchNot[T any](matcher Matcher[T]) *notMatcher[T] {
+	return &notMatcher[T]{matcher: matcher}
+}
+
+// MatchAll returns a matcher which matches messages which are matched by ALL
+// of the matchers provided. When a message is not matched, the trace
+// reports the first matcher (clause) which rejected it.
+func MatchAll[T any](matchers ...Matcher[T]) *allMatcher[T] {
+	return &allMatcher[T]{matchers: matchers}
+}
+
+// MatchAnyOf returns a matcher which matches messages which are matched by ANY
+// of the matchers provided. When a message is not matched, the trace
+// reports why each of the matchers (clauses) rejected it.
+func MatchAnyOf[T any](matchers ...Matcher[T]) *anyOfMatcher[T] {
+	return &anyOfMatcher[T]{matchers: matchers}
+}
+
+// MatchAnything returns a matcher which matches every message.
+func MatchAnything[T any]() *anythingMatcher[T] {
+	return &anythingMatcher[T]{}
+}
+
+// NamedMatcher names the matcher provided, so that it is referred to by name (rather than
+// by its index) in traces. The returned matcher otherwise behaves identically to the matcher provided.
+func NamedMatcher[T any](name string, matcher Matcher[T]) *namedMatcher[T] {
+	return &namedMatcher[T]{name: name, matcher: matcher}
+}
+
 // MatchStructPartial returns a matcher which tests that
 // all non-zero values inside of the provided struct
 // match the same fields inside of the messages received. That is
@@ -85,76 +132,471 @@ func (eqMatch *equalMatcher[T]) DoesMatch(t T) bool {
 	return t == eqMatch.target
 }
 
+func (eqMatch *equalMatcher[T]) Describe() string {
+	return eqMatch.describeWith(defaultValueFormatter)
+}
+
+func (eqMatch *equalMatcher[T]) describeWith(values *valueFormatter) string {
+	return "equals " + formatTarget(values, eqMatch.target, values.formatValue)
+}
+
 type stringContainsMatcher struct{ target string }
 
 func (contains *stringContainsMatcher) DoesMatch(message string) bool {
 	return strings.Contains(message, contains.target)
 }
 
+func (contains *stringContainsMatcher) Describe() string {
+	return fmt.Sprintf("contains %q", contains.target)
+}
+
 type structEqualMatcher[T any] struct{ target T }
 
 func (eqMatch *structEqualMatcher[T]) DoesMatch(t T) bool {
 	return reflect.DeepEqual(t, eqMatch.target)
 }
 
+func (eqMatch *structEqualMatcher[T]) Describe() string {
+	return eqMatch.describeWith(defaultValueFormatter)
+}
+
+func (eqMatch *structEqualMatcher[T]) describeWith(values *valueFormatter) string {
+	return "deeply equals " + formatTarget(values, eqMatch.target, values.formatWhole)
+}
+
+// ExplainMismatch describes each field of the message which differs from
+// the target. Nested structs are compared field-by-field.
+func (eqMatch *structEqualMatcher[T]) ExplainMismatch(t T) string {
+	return eqMatch.explainMismatch(defaultValueFormatter, t)
+}
+
+func (eqMatch *structEqualMatcher[T]) explainMismatch(values *valueFormatter, t T) string {
+	diffs := diffValues(values, "", reflect.ValueOf(eqMatch.target), reflect.ValueOf(t))
+	return strings.Join(diffs, ", ")
+}
+
+// explainMatch explains the mismatch using the formatter of the scope,
+// so that the fields it redacts do not appear in the trace.
+func (eqMatch *structEqualMatcher[T]) explainMatch(message T, scope *captureScope) (bool, string) {
+	if eqMatch.DoesMatch(message) {
+		return true, ""
+	}
+
+	return false, orNoMatch(eqMatch.explainMismatch(scope.formatter(), message))
+}
+
 type predicateMatcher[T any] struct{ predicate func(T) bool }
 
 func (predMatcher *predicateMatcher[T]) DoesMatch(message T) bool {
 	return predMatcher.predicate(message)
 }
 
+func (predMatcher *predicateMatcher[T]) Describe() string {
+	return "matches predicate"
+}
+
 type structFieldMatcher[T any] struct{ fieldsAndValues map[string]any }
 
 func (fieldEqMatch *structFieldMatcher[T]) DoesMatch(t T) bool {
-	defer func() {
-		// Ignore. If we hit a panic while trying to
-		// handle this match, then it's clear it doesn't match. Zero-value
-		// bool of false is accurate.
-		_ = recover()
-	}()
-
-	rt := reflect.TypeOf(t)
 	rv := reflect.ValueOf(t)
-
-	if rt.Kind() != reflect.Struct {
+	if rv.Kind() != reflect.Struct {
 		return false
 	}
 
 	// Iterate over fieldsAndValues and compare with fields in t
 	for field, expectedValue := range fieldEqMatch.fieldsAndValues {
-		fieldValue := rv.FieldByName(field)
-		if !fieldValue.IsValid() {
+		if fieldEqMatch.fieldMismatch(defaultValueFormatter, rv, field, expectedValue) != "" {
 			return false
 		}
+	}
 
-		// If the expectedValue is a function, attempt to call it
-		// and return false for this message if the return value is false.
-		if reflect.TypeOf(expectedValue).Kind() == reflect.Func {
-			funcValue := reflect.ValueOf(expectedValue)
-			in := []reflect.Value{fieldValue}
-			returnValues := funcValue.Call(in)
+	return true
+}
 
-			// Expect a single bool return value
-			if len(returnValues) != 1 || returnValues[0].Kind() != reflect.Bool {
-				panic("Expected matching function to return a single boolean value")
-			}
+// Describe lists the fields (and their expected values) which are
+// matched, in order of the field names.
+func (fieldEqMatch *structFieldMatcher[T]) Describe() string {
+	return fieldEqMatch.describeWith(defaultValueFormatter)
+}
+
+func (fieldEqMatch *structFieldMatcher[T]) describeWith(values *valueFormatter) string {
+	fields := make([]string, 0, len(fieldEqMatch.fieldsAndValues))
+	for field, expectedValue := range fieldEqMatch.fieldsAndValues {
+		switch {
+		case expectedValue != nil && reflect.TypeOf(expectedValue).Kind() == reflect.Func:
+			fields = append(fields, fmt.Sprintf("%s=<predicate>", field))
+		case fieldEqMatch.isRedacted(values, field):
+			fields = append(fields, fmt.Sprintf("%s=%s", field, redacted))
+		default:
+			fields = append(fields, fmt.Sprintf("%s=%s", field, values.formatValue(reflect.ValueOf(expectedValue))))
+		}
+	}
+	sort.Strings(fields)
+
+	return fmt.Sprintf("has fields {%s}", strings.Join(fields, ", "))
+}
+
+// ExplainMismatch describes each of the fields of the message which
+// do not match, in order of the field names.
+func (fieldEqMatch *structFieldMatcher[T]) ExplainMismatch(t T) string {
+	return fieldEqMatch.explainMismatch(defaultValueFormatter, t)
+}
+
+func (fieldEqMatch *structFieldMatcher[T]) explainMismatch(values *valueFormatter, t T) string {
+	rv := reflect.ValueOf(t)
+	if rv.Kind() != reflect.Struct {
+		return fmt.Sprintf("message is not a struct (got %T)", t)
+	}
+
+	fields := make([]string, 0, len(fieldEqMatch.fieldsAndValues))
+	for field := range fieldEqMatch.fieldsAndValues {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	mismatches := make([]string, 0)
+	for _, field := range fields {
+		if mismatch := fieldEqMatch.fieldMismatch(values, rv, field, fieldEqMatch.fieldsAndValues[field]); mismatch != "" {
+			mismatches = append(mismatches, mismatch)
+		}
+	}
+
+	return strings.Join(mismatches, ", ")
+}
+
+// explainMatch explains the mismatch using the formatter of the scope,
+// so that the fields it redacts do not appear in the trace.
+func (fieldEqMatch *structFieldMatcher[T]) explainMatch(message T, scope *captureScope) (bool, string) {
+	if fieldEqMatch.DoesMatch(message) {
+		return true, ""
+	}
+
+	return false, orNoMatch(fieldEqMatch.explainMismatch(scope.formatter(), message))
+}
+
+// isRedacted returns true if the formatter provided redacts the field of the message with the name provided.
+func (fieldEqMatch *structFieldMatcher[T]) isRedacted(values *valueFormatter, name string) bool {
+	field := reflect.StructField{Name: name}
+	if rt := reflect.TypeFor[T](); rt.Kind() == reflect.Struct {
+		if structField, ok := rt.FieldByName(name); ok {
+			field = structField
+		}
+	}
+
+	return values.isRedacted(field)
+}
+
+// fieldMismatch compares the field of the message with the expected value,
+// returning a description of the mismatch (or an empty string if the field matches).
+func (fieldEqMatch *structFieldMatcher[T]) fieldMismatch(values *valueFormatter, rv reflect.Value, field string, expectedValue any) (mismatch string) {
+	defer func() {
+		// If we hit a panic while trying to handle this field (e.g. the
+		// field is unexported), then it's clear it doesn't match.
+		if r := recover(); r != nil {
+			mismatch = fmt.Sprintf("field %s could not be compared (%v)", field, r)
+		}
+	}()
+
+	fieldValue := rv.FieldByName(field)
+	if !fieldValue.IsValid() {
+		return fmt.Sprintf("field %s is missing", field)
+	}
+
+	// The values of redacted fields must not appear in the trace
+	format := values.formatValue
+	if structField, ok := rv.Type().FieldByName(field); ok && values.isRedacted(structField) {
+		format = func(reflect.Value) string { return redacted }
+	}
+
+	// If the expectedValue is a function, attempt to call it
+	// and return false for this message if the return value is false.
+	if reflect.TypeOf(expectedValue).Kind() == reflect.Func {
+		funcValue := reflect.ValueOf(expectedValue)
+		in := []reflect.Value{fieldValue}
+		returnValues := funcValue.Call(in)
+
+		// Expect a single bool return value
+		if len(returnValues) != 1 || returnValues[0].Kind() != reflect.Bool {
+			panic("Expected matching function to return a single boolean value")
+		}
+
+		if !returnValues[0].Bool() {
+			return fmt.Sprintf("field %s: predicate returned false for %s", field, format(fieldValue))
+		}
+
+		return ""
+	}
+
+	if !fieldValue.Type().AssignableTo(reflect.TypeOf(expectedValue)) {
+		return fmt.Sprintf("field %s: type %s is not assignable to %T", field, fieldValue.Type(), expectedValue)
+	}
+
+	if fieldValue.Kind() == reflect.Interface && expectedValue == nil {
+		return ""
+	} else if !reflect.DeepEqual(fieldValue.Interface(), expectedValue) {
+		if format(fieldValue) == redacted {
+			return fmt.Sprintf("field %s: values differ (%s)", field, redacted)
+		}
+
+		return fmt.Sprintf("field %s: expected %s, got %s", field, format(reflect.ValueOf(expectedValue)), format(fieldValue))
+	}
+
+	return ""
+}
+
+// diffValues returns a description of each difference between the expected and actual
+// values. Structs are compared field-by-field (with the path of nested fields joined by '.'),
+// while all other values are compared as a whole.
+func diffValues(values *valueFormatter, path string, expected reflect.Value, actual reflect.Value) []string {
+	describe := func(description string) string {
+		if path == "" {
+			return description
+		}
+
+		return fmt.Sprintf("field %s: %s", path, description)
+	}
+
+	if !expected.IsValid() || !actual.IsValid() || expected.Type() != actual.Type() {
+		return []string{describe(fmt.Sprintf("expected %s, got %s", values.formatValue(expected), values.formatValue(actual)))}
+	}
+
+	if expected.Kind() != reflect.Struct {
+		if valuesEqual(expected, actual) {
+			return nil
+		}
 
-			if !returnValues[0].Bool() {
-				return false
+		return []string{describe(fmt.Sprintf("expected %s, got %s", values.formatValue(expected), values.formatValue(actual)))}
+	}
+
+	diffs := make([]string, 0)
+	for i := 0; i < expected.NumField(); i++ {
+		name := expected.Type().Field(i).Name
+		if path != "" {
+			name = path + "." + name
+		}
+
+		// The values of redacted fields must not appear in the trace
+		if values.isRedacted(expected.Type().Field(i)) {
+			if !valuesEqual(expected.Field(i), actual.Field(i)) {
+				diffs = append(diffs, fmt.Sprintf("field %s: values differ (%s)", name, redacted))
 			}
+
 			continue
 		}
 
-		if !fieldValue.Type().AssignableTo(reflect.TypeOf(expectedValue)) {
-			return false
+		diffs = append(diffs, diffValues(values, name, expected.Field(i), actual.Field(i))...)
+	}
+
+	return diffs
+}
+
+// valuesEqual reports whether the two values are deeply equal. Values obtained
+// from unexported fields cannot be passed to reflect.DeepEqual, and so
+// are instead compared using their Go-syntax representation.
+func valuesEqual(a reflect.Value, b reflect.Value) bool {
+	if a.CanInterface() && b.CanInterface() {
+		return reflect.DeepEqual(a.Interface(), b.Interface())
+	}
+
+	return fmt.Sprintf("%#v", a) == fmt.Sprintf("%#v", b)
+}
+
+type namedMatcher[T any] struct {
+	name    string
+	matcher Matcher[T]
+}
+
+func (named *namedMatcher[T]) DoesMatch(message T) bool {
+	return named.matcher.DoesMatch(message)
+}
+
+func (named *namedMatcher[T]) doesMatchCaptures(message T, scope *captureScope) bool {
+	return matchWithCaptures(named.matcher, message, scope)
+}
+
+func (named *namedMatcher[T]) Name() string {
+	return named.name
+}
+
+func (named *namedMatcher[T]) Describe() string {
+	return describe(named.matcher)
+}
+
+func (named *namedMatcher[T]) describeWith(values *valueFormatter) string {
+	return describeWith(named.matcher, values)
+}
+
+func (named *namedMatcher[T]) explainMatch(message T, scope *captureScope) (bool, string) {
+	return explainMatch(named.matcher, message, scope)
+}
+
+func (named *namedMatcher[T]) capture(message T, scope *captureScope) Captures {
+	return captureMatched(named.matcher, message, scope)
+}
+
+type notMatcher[T any] struct{ matcher Matcher[T] }
+
+func (not *notMatcher[T]) DoesMatch(message T) bool {
+	ok, _ := not.explainMatch(message, nil)
+	return ok
+}
+
+func (not *notMatcher[T]) doesMatchCaptures(message T, scope *captureScope) bool {
+	ok, _ := not.explainMatch(message, scope)
+	return ok
+}
+
+func (not *notMatcher[T]) Describe() string {
+	return not.describeWith(defaultValueFormatter)
+}
+
+func (not *notMatcher[T]) describeWith(values *valueFormatter) string {
+	return fmt.Sprintf("not (%s)", describeWith(not.matcher, values))
+}
+
+func (not *notMatcher[T]) explainMatch(message T, scope *captureScope) (bool, string) {
+	if matchWithCaptures(not.matcher, message, scope) {
+		return false, fmt.Sprintf("negated matcher matched (Not clause #0: %s)", describeWith(not.matcher, scope.formatter()))
+	}
+
+	return true, ""
+}
+
+type allMatcher[T any] struct{ matchers []Matcher[T] }
+
+func (all *allMatcher[T]) DoesMatch(message T) bool {
+	ok, _ := all.explainMatch(message, nil)
+	return ok
+}
+
+func (all *allMatcher[T]) doesMatchCaptures(message T, scope *captureScope) bool {
+	ok, _ := all.explainMatch(message, scope)
+	return ok
+}
+
+func (all *allMatcher[T]) Describe() string {
+	return all.describeWith(defaultValueFormatter)
+}
+
+func (all *allMatcher[T]) describeWith(values *valueFormatter) string {
+	return fmt.Sprintf("all of (%s)", strings.Join(describeMatchers(all.matchers, values), ", "))
+}
+
+func (all *allMatcher[T]) explainMatch(message T, scope *captureScope) (bool, string) {
+	for idx, m := range all.matchers {
+		if ok, reason := explainMatch(m, message, scope); !ok {
+			return false, fmt.Sprintf("%s (And clause #%d: %s)", reason, idx, describeWith(m, scope.formatter()))
 		}
+	}
 
-		if fieldValue.Kind() == reflect.Interface && expectedValue == nil {
-			continue
-		} else if !reflect.DeepEqual(fieldValue.Interface(), expectedValue) {
-			return false
+	return true, ""
+}
+
+// capture binds the values captured by each of the matchers, as
+// all of them must have matched the message.
+func (all *allMatcher[T]) capture(message T, scope *captureScope) Captures {
+	var captured Captures
+	for _, m := range all.matchers {
+		for name, value := range captureMatched(m, message, scope) {
+			if captured == nil {
+				captured = make(Captures)
+			}
+			captured[name] = value
 		}
 	}
 
+	return captured
+}
+
+type anyOfMatcher[T any] struct{ matchers []Matcher[T] }
+
+func (anyOf *anyOfMatcher[T]) DoesMatch(message T) bool {
+	ok, _ := anyOf.explainMatch(message, nil)
+	return ok
+}
+
+func (anyOf *anyOfMatcher[T]) doesMatchCaptures(message T, scope *captureScope) bool {
+	ok, _ := anyOf.explainMatch(message, scope)
+	return ok
+}
+
+func (anyOf *anyOfMatcher[T]) Describe() string {
+	return anyOf.describeWith(defaultValueFormatter)
+}
+
+func (anyOf *anyOfMatcher[T]) describeWith(values *valueFormatter) string {
+	return fmt.Sprintf("any of (%s)", strings.Join(describeMatchers(anyOf.matchers, values), ", "))
+}
+
+func (anyOf *anyOfMatcher[T]) explainMatch(message T, scope *captureScope) (bool, string) {
+	if len(anyOf.matchers) == 0 {
+		return false, "no clauses to match (Or)"
+	}
+
+	reasons := make([]string, 0, len(anyOf.matchers))
+	for idx, m := range anyOf.matchers {
+		ok, reason := explainMatch(m, message, scope)
+		if ok {
+			return true, ""
+		}
+
+		reasons = append(reasons, fmt.Sprintf("%s (Or clause #%d: %s)", reason, idx, describeWith(m, scope.formatter())))
+	}
+
+	return false, strings.Join(reasons, "; ")
+}
+
+// capture binds the values captured by the first
+// matcher which matched the message.
+func (anyOf *anyOfMatcher[T]) capture(message T, scope *captureScope) Captures {
+	for _, m := range anyOf.matchers {
+		if matchWithCaptures(m, message, scope) {
+			return captureMatched(m, message, scope)
+		}
+	}
+
+	return nil
+}
+
+type anythingMatcher[T any] struct{}
+
+func (anything *anythingMatcher[T]) DoesMatch(T) bool {
 	return true
 }
+
+func (anything *anythingMatcher[T]) Describe() string {
+	return "anything"
+}
+
+// explainingMatcher is implemented by matchers which are able to
+// describe why a message was not matched.
+type explainingMatcher[T any] interface {
+	explainMatch(message T, scope *captureScope) (bool, string)
+}
+
+// explainMatch matches the message against the matcher, returning a description of
+// why the message was not matched (if the matcher is able to provide one).
+func explainMatch[T any](matcher Matcher[T], message T, scope *captureScope) (bool, string) {
+	if explaining, ok := matcher.(explainingMatcher[T]); ok {
+		return explaining.explainMatch(message, scope)
+	}
+
+	if matchWithCaptures(matcher, message, scope) {
+		return true, ""
+	}
+
+	if explaining, ok := matcher.(ExplainingMatcher[T]); ok {
+		return false, orNoMatch(explaining.ExplainMismatch(message))
+	}
+
+	return false, "no match"
+}
+
+// orNoMatch returns the reason provided, or "no match" if it is empty.
+func orNoMatch(reason string) string {
+	if reason == "" {
+		return "no match"
+	}
+
+	return reason
+}
@@ -1,6 +1,8 @@
 package chanassert_test
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/hbomb79/go-chanassert"
@@ -500,3 +502,224 @@ func Test_MatchPredicate(t *testing.T) {
 
 	runMatcherTests(t, matcher, tests)
 }
+
+func Test_MatchNot(t *testing.T) {
+	matcher := chanassert.MatchNot(chanassert.MatchStringContains("error"))
+	tests := []matcherTest[string]{
+		{
+			summary:      "Negated matcher does not match",
+			value:        "status: ok",
+			shouldAccept: true,
+		},
+		{
+			summary:      "Negated matcher matches",
+			value:        "status: error",
+			shouldAccept: false,
+		},
+	}
+
+	runMatcherTests(t, matcher, tests)
+}
+
+func Test_MatchAll(t *testing.T) {
+	matcher := chanassert.MatchAll(
+		chanassert.MatchStringContains("status"),
+		chanassert.MatchNot(chanassert.MatchStringContains("error")),
+	)
+	tests := []matcherTest[string]{
+		{
+			summary:      "All matchers match",
+			value:        "status: ok",
+			shouldAccept: true,
+		},
+		{
+			summary:      "First matcher does not match",
+			value:        "ok",
+			shouldAccept: false,
+		},
+		{
+			summary:      "Second matcher does not match",
+			value:        "status: error",
+			shouldAccept: false,
+		},
+	}
+
+	runMatcherTests(t, matcher, tests)
+	runMatcherTests(t, chanassert.MatchAll[string](), []matcherTest[string]{
+		{summary: "No matchers", value: "anything", shouldAccept: true},
+	})
+}
+
+func Test_MatchAnyOf(t *testing.T) {
+	matcher := chanassert.MatchAnyOf(
+		chanassert.MatchEqual("hello"),
+		chanassert.MatchStringContains("world"),
+	)
+	tests := []matcherTest[string]{
+		{
+			summary:      "First matcher matches",
+			value:        "hello",
+			shouldAccept: true,
+		},
+		{
+			summary:      "Second matcher matches",
+			value:        "hello, world",
+			shouldAccept: true,
+		},
+		{
+			summary:      "No matchers match",
+			value:        "goodbye",
+			shouldAccept: false,
+		},
+	}
+
+	runMatcherTests(t, matcher, tests)
+	runMatcherTests(t, chanassert.MatchAnyOf[string](), []matcherTest[string]{
+		{summary: "No matchers", value: "anything", shouldAccept: false},
+	})
+}
+
+func Test_MatchAnything(t *testing.T) {
+	tests := []matcherTest[string]{
+		{
+			summary:      "Any message",
+			value:        "hello",
+			shouldAccept: true,
+		},
+		{
+			summary:      "Empty message",
+			value:        "",
+			shouldAccept: true,
+		},
+	}
+
+	runMatcherTests(t, chanassert.MatchAnything[string](), tests)
+}
+
+func Test_MatcherAlgebra_Trace(t *testing.T) {
+	combiner := chanassert.OneOf(
+		chanassert.MatchAll(
+			chanassert.MatchStringContains("status"),
+			chanassert.MatchNot(chanassert.MatchStringContains("error")),
+		),
+		chanassert.MatchAnyOf(chanassert.MatchEqual("a"), chanassert.MatchEqual("b")),
+	)
+
+	_, trace := combiner.TryMatch("status: error")
+	builder := &strings.Builder{}
+	trace.PrintTrace(builder, false, 0)
+
+	expected := []string{
+		`Matcher #0 (all of (contains "status", not (contains "error"))) REJECT: negated matcher matched (Not clause #0: contains "error") (And clause #1: not (contains "error"))`,
+		`Matcher #1 (any of (equals "a", equals "b")) REJECT: no match (Or clause #0: equals "a"); no match (Or clause #1: equals "b")`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(builder.String(), line+"\n") {
+			t.Errorf("expected trace to contain line %q, but it did not:\n%s", line, builder.String())
+		}
+	}
+}
+
+// statusMatcher is a custom matcher which explains mismatches.
+type statusMatcher struct{ status int }
+
+func (m statusMatcher) DoesMatch(code int) bool { return code == m.status }
+
+func (m statusMatcher) ExplainMismatch(code int) string {
+	return fmt.Sprintf("status %d != %d", code, m.status)
+}
+
+func Test_ExplainMismatch(t *testing.T) {
+	type inner struct {
+		x string
+	}
+	type response struct {
+		inner
+		Status int
+		Body   string
+		Tags   []string
+	}
+
+	target := response{inner: inner{x: "a"}, Status: 200, Body: "ok", Tags: []string{"x"}}
+
+	tests := []struct {
+		summary  string
+		matcher  chanassert.ExplainingMatcher[response]
+		message  response
+		expected string
+	}{
+		{
+			summary:  "MatchStruct top level mismatch",
+			matcher:  chanassert.MatchStruct(target),
+			message:  response{inner: inner{x: "a"}, Status: 404, Body: "ok", Tags: []string{"x"}},
+			expected: "field Status: expected 200, got 404",
+		},
+		{
+			summary:  "MatchStruct nested and slice mismatch",
+			matcher:  chanassert.MatchStruct(target),
+			message:  response{inner: inner{x: "b"}, Status: 200, Body: "ok", Tags: []string{"y"}},
+			expected: `field inner.x: expected "a", got "b", field Tags: expected [x], got [y]`,
+		},
+		{
+			summary:  "MatchStructPartial mismatch",
+			matcher:  chanassert.MatchStructPartial(response{Status: 200, Body: "ok"}),
+			message:  response{Status: 500, Body: "error"},
+			expected: `field Body: expected "ok", got "error", field Status: expected 200, got 500`,
+		},
+		{
+			summary:  "MatchStructFields missing field",
+			matcher:  chanassert.MatchStructFields[response](map[string]any{"Code": 200}),
+			message:  target,
+			expected: "field Code is missing",
+		},
+		{
+			summary:  "MatchStructFields wrong type",
+			matcher:  chanassert.MatchStructFields[response](map[string]any{"Status": "200"}),
+			message:  target,
+			expected: "field Status: type int is not assignable to string",
+		},
+		{
+			summary: "MatchStructFields predicate",
+			matcher: chanassert.MatchStructFields[response](map[string]any{
+				"Status": func(status int) bool { return status >= 500 },
+			}),
+			message:  target,
+			expected: "field Status: predicate returned false for 200",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.summary, func(t *testing.T) {
+			t.Parallel()
+
+			if test.matcher.DoesMatch(test.message) {
+				t.Fatalf("Matcher ACCEPTED message '%v', but it was expected to reject it", test.message)
+			}
+
+			if explanation := test.matcher.ExplainMismatch(test.message); explanation != test.expected {
+				t.Errorf("expected mismatch explanation %q, got %q", test.expected, explanation)
+			}
+		})
+	}
+}
+
+func Test_ExplainMismatch_Trace(t *testing.T) {
+	combiner := chanassert.OneOf[int](
+		statusMatcher{status: 200},
+		chanassert.MatchAll[int](chanassert.MatchAnything[int](), statusMatcher{status: 201}),
+	)
+
+	_, trace := combiner.TryMatch(404)
+	builder := &strings.Builder{}
+	trace.PrintTrace(builder, false, 0)
+
+	expected := []string{
+		"Matcher #0 REJECT: status 404 != 200",
+		"Matcher #1 (all of (anything, chanassert_test.statusMatcher)) REJECT: status 404 != 201 (And clause #1: chanassert_test.statusMatcher)",
+	}
+	for _, line := range expected {
+		if !strings.Contains(builder.String(), line+"\n") {
+			t.Errorf("expected trace to contain line %q, but it did not:\n%s", line, builder.String())
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package chanassert
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// NearMiss describes the matcher which came closest to accepting a rejected message, searching
+// the layer which rejected it as well as every other layer. It is attached to a [RejectionError],
+// and is intended to make mistakes in the ordering of messages easier to spot.
+type NearMiss struct {
+	LayerIdx  int    `json:"layer_idx"`
+	LayerName string `json:"layer_name,omitempty"`
+
+	// Path locates the matcher within its layer (e.g. "combiner #1, matcher #0").
+	Path        string `json:"path"`
+	Description string `json:"description"`
+
+	// Matched is true if the matcher would have accepted the message. This is only
+	// reported for layers other than the one which rejected the message, as the trace
+	// of the message already explains why its own layer rejected it.
+	Matched bool `json:"matched"`
+
+	// Mismatch describes how the message differed from the matcher if it did not
+	// match, and Differences is the number of values (e.g. fields) which differed.
+	Mismatch    string `json:"mismatch,omitempty"`
+	Differences int    `json:"differences,omitempty"`
+
+	rejectedLayerIdx int
+}
+
+func (miss NearMiss) String() string {
+	matcher := fmt.Sprintf("%s, %s (%s)", nameLabel("layer", miss.LayerIdx, miss.LayerName), miss.Path, miss.Description)
+	switch {
+	case miss.Matched && miss.LayerIdx > miss.rejectedLayerIdx:
+		return fmt.Sprintf("would have matched %s: did it arrive too early?", matcher)
+	case miss.Matched:
+		return fmt.Sprintf("would have matched %s: did it arrive too late?", matcher)
+	case miss.Differences == 1:
+		return fmt.Sprintf("closest match is %s, which differs only in %s", matcher, miss.Mismatch)
+	default:
+		return fmt.Sprintf("closest match is %s, which differs in %s", matcher, miss.Mismatch)
+	}
+}
+
+// nearMisser is implemented by matchers which are able to measure how close a message came
+// to matching: the number of values which differed, out of the number of values compared.
+type nearMisser[T any] interface {
+	nearMiss(message T) (differences int, compared int)
+}
+
+// opaqueMatcher is implemented by matchers which call a predicate provided by the test (e.g.
+// [MatchPredicate]). As the predicate may have side effects, these matchers are never called
+// when searching for a near miss. Matchers which wrap other matchers are opaque if any of them are.
+type opaqueMatcher interface {
+	isOpaque() bool
+}
+
+func isOpaque[T any](matcher Matcher[T]) bool {
+	if opaque, ok := matcher.(opaqueMatcher); ok {
+		return opaque.isOpaque()
+	}
+
+	return false
+}
+
+func anyOpaque[T any](matchers []Matcher[T]) bool {
+	for _, m := range matchers {
+		if isOpaque(m) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matcherHolder is implemented by layers and combiners which hold matchers, and calls the
+// visit function with each of them, along with the labels which locate the matcher.
+type matcherHolder[T any] interface {
+	visitMatchers(path []string, visit func(path []string, matcher Matcher[T]))
+}
+
+func visitMatcherList[T any](path []string, matchers []Matcher[T], visit func(path []string, matcher Matcher[T])) {
+	for idx, m := range matchers {
+		visit(append(path[:len(path):len(path)], label("matcher", idx, m)), m)
+	}
+}
+
+func visitCombinerMatchers[T any](path []string, combiner Combiner[T], visit func(path []string, matcher Matcher[T])) {
+	if holder, ok := combiner.(matcherHolder[T]); ok {
+		holder.visitMatchers(path, visit)
+	}
+}
+
+// measureNearMiss returns the number of differences between the message and the matcher,
+// if the matcher is able to measure them and the message matched at least one of the values compared.
+func measureNearMiss[T any](matcher Matcher[T], message T) (int, bool) {
+	misser, ok := matcher.(nearMisser[T])
+	if !ok {
+		return 0, false
+	}
+
+	differences, compared := misser.nearMiss(message)
+	return differences, differences > 0 && differences < compared
+}
+
+// findNearMiss searches the layers for the matcher which came closest to accepting the message
+// which was rejected by the layer at the index provided, using the values captured so far. Matchers in other layers which would have
+// accepted the message are preferred (searching the following layers first, as messages which arrive
+// early are the most common mistake), followed by the matcher with the fewest differences (preferring
+// the layer which rejected the message, and then those closest to it). Opaque matchers are skipped.
+func (exp *expecter[T]) findNearMiss(message T, rejectedIdx int) *NearMiss {
+	order := []int{rejectedIdx}
+	for idx := rejectedIdx + 1; idx < len(exp.expectLayers); idx++ {
+		order = append(order, idx)
+	}
+	for idx := rejectedIdx - 1; idx >= 0; idx-- {
+		order = append(order, idx)
+	}
+
+	var closest *NearMiss
+	for _, layerIdx := range order {
+		holder, ok := exp.expectLayers[layerIdx].(matcherHolder[T])
+		if !ok {
+			continue
+		}
+
+		holder.visitMatchers(nil, func(path []string, matcher Matcher[T]) {
+			if (closest != nil && closest.Matched) || isOpaque(matcher) {
+				return
+			}
+
+			miss := &NearMiss{
+				LayerIdx:         layerIdx,
+				LayerName:        nameOf(exp.expectLayers[layerIdx]),
+				Path:             strings.Join(path, ", "),
+				Description:      describeWith(matcher, exp.captures.formatter()),
+				rejectedLayerIdx: rejectedIdx,
+			}
+
+			ok, reason := explainMatch(matcher, message, exp.captures)
+			if ok {
+				if layerIdx != rejectedIdx {
+					miss.Matched = true
+					closest = miss
+				}
+
+				return
+			}
+
+			if differences, near := measureNearMiss(matcher, message); near && (closest == nil || differences < closest.Differences) {
+				miss.Mismatch, miss.Differences = reason, differences
+				closest = miss
+			}
+		})
+	}
+
+	return closest
+}
+
+func (layer *layer[T]) visitMatchers(path []string, visit func(path []string, matcher Matcher[T])) {
+	for idx, c := range layer.combiners {
+		visitCombinerMatchers(append(path[:len(path):len(path)], label("combiner", idx, c)), c, visit)
+	}
+}
+
+func (nCombiner *nCombiner[T]) visitMatchers(path []string, visit func(path []string, matcher Matcher[T])) {
+	visitMatcherList(path, nCombiner.matchers, visit)
+}
+
+func (ordered *orderedCombiner[T]) visitMatchers(path []string, visit func(path []string, matcher Matcher[T])) {
+	visitMatcherList(path, ordered.matchers, visit)
+}
+
+func (keyed *keyedCombiner[T, K]) visitMatchers(path []string, visit func(path []string, matcher Matcher[T])) {
+	visitMatcherList(path, keyed.matchers, visit)
+}
+
+func (composite *compositeCombiner[T]) visitMatchers(path []string, visit func(path []string, matcher Matcher[T])) {
+	for idx, c := range composite.combiners {
+		visitCombinerMatchers(append(path[:len(path):len(path)], label("combiner", idx, c)), c, visit)
+	}
+}
+
+func (optional *optionalCombiner[T]) visitMatchers(path []string, visit func(path []string, matcher Matcher[T])) {
+	visitCombinerMatchers(path, optional.combiner, visit)
+}
+
+func (eqMatch *structEqualMatcher[T]) nearMiss(t T) (int, int) {
+	target := reflect.ValueOf(eqMatch.target)
+	return len(diffValues(defaultValueFormatter, "", target, reflect.ValueOf(t))), countValues(target)
+}
+
+func (fieldEqMatch *structFieldMatcher[T]) nearMiss(t T) (int, int) {
+	rv := reflect.ValueOf(t)
+	if rv.Kind() != reflect.Struct {
+		return 0, 0
+	}
+
+	differences := 0
+	for field, expectedValue := range fieldEqMatch.fieldsAndValues {
+		if fieldEqMatch.fieldMismatch(defaultValueFormatter, rv, field, expectedValue) != "" {
+			differences++
+		}
+	}
+
+	return differences, len(fieldEqMatch.fieldsAndValues)
+}
+
+func (named *namedMatcher[T]) nearMiss(message T) (int, int) {
+	if misser, ok := named.matcher.(nearMisser[T]); ok {
+		return misser.nearMiss(message)
+	}
+
+	return 0, 0
+}
+
+func (capture *captureMatcher[T]) nearMiss(message T) (int, int) {
+	if misser, ok := capture.matcher.(nearMisser[T]); ok {
+		return misser.nearMiss(message)
+	}
+
+	return 0, 0
+}
+
+func (predMatcher *predicateMatcher[T]) isOpaque() bool { return true }
+
+func (captured *capturedMatcher[T]) isOpaque() bool { return captured.opaque }
+
+func (capture *captureMatcher[T]) isOpaque() bool { return isOpaque(capture.matcher) }
+
+func (named *namedMatcher[T]) isOpaque() bool { return isOpaque(named.matcher) }
+
+func (not *notMatcher[T]) isOpaque() bool { return isOpaque(not.matcher) }
+
+func (all *allMatcher[T]) isOpaque() bool { return anyOpaque(all.matchers) }
+
+func (anyOf *anyOfMatcher[T]) isOpaque() bool { return anyOpaque(anyOf.matchers) }
+
+// countValues returns the number of values compared by diffValues
+// for the value provided (i.e. the number of non-struct fields).
+func countValues(v reflect.Value) int {
+	if !v.IsValid() || v.Kind() != reflect.Struct {
+		return 1
+	}
+
+	count := 0
+	for i := 0; i < v.NumField(); i++ {
+		count += countValues(v.Field(i))
+	}
+
+	return count
+}
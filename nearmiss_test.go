@@ -0,0 +1,191 @@
+package chanassert_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hbomb79/go-chanassert"
+)
+
+func Test_NearMiss(t *testing.T) {
+	type response struct {
+		Status int
+		Body   string
+	}
+
+	tests := []struct {
+		summary  string
+		layers   [][]chanassert.Matcher[response]
+		messages []response
+		expected string
+	}{
+		{
+			summary: "Arrived too early",
+			layers: [][]chanassert.Matcher[response]{
+				{chanassert.MatchStruct(response{200, "a"})},
+				{chanassert.MatchStruct(response{200, "b"})},
+			},
+			messages: []response{{200, "b"}, {200, "a"}},
+			expected: `would have matched layer #1, combiner #0, matcher #0 (deeply equals {Status:200 Body:b}): did it arrive too early?`,
+		},
+		{
+			summary: "Arrived too late",
+			layers: [][]chanassert.Matcher[response]{
+				{chanassert.MatchStruct(response{200, "a"})},
+				{chanassert.MatchStruct(response{200, "b"})},
+				{chanassert.MatchStruct(response{200, "c"})},
+			},
+			messages: []response{{200, "a"}, {200, "b"}, {200, "a"}},
+			expected: `would have matched layer #0, combiner #0, matcher #0 (deeply equals {Status:200 Body:a}): did it arrive too late?`,
+		},
+		{
+			summary: "Differs in a single field",
+			layers: [][]chanassert.Matcher[response]{
+				{chanassert.MatchEqual(response{200, "ok"}), chanassert.MatchStructPartial(response{Status: 200, Body: "ok"})},
+			},
+			messages: []response{{404, "ok"}},
+			expected: `closest match is layer #0, combiner #0, matcher #1 (has fields {Body="ok", Status=200}), which differs only in field Status: expected 200, got 404`,
+		},
+		{
+			summary: "Closest of several fields",
+			layers: [][]chanassert.Matcher[response]{
+				{chanassert.MatchStruct(response{500, "error"})},
+				{chanassert.NamedMatcher("created", chanassert.MatchStruct(response{201, "created"}))},
+			},
+			messages: []response{{201, "error"}},
+			expected: `closest match is layer #0, combiner #0, matcher #0 (deeply equals {Status:500 Body:error}), which differs only in field Status: expected 500, got 201`,
+		},
+		{
+			summary: "Named matcher",
+			layers: [][]chanassert.Matcher[response]{
+				{chanassert.MatchStruct(response{500, "error"})},
+				{chanassert.NamedMatcher("created", chanassert.MatchStruct(response{201, "created"}))},
+			},
+			messages: []response{{201, "createdd"}},
+			expected: `closest match is layer #1, combiner #0, matcher 'created' (deeply equals {Status:201 Body:created}), which differs only in field Body: expected "created", got "createdd"`,
+		},
+		{
+			summary: "Nothing close",
+			layers: [][]chanassert.Matcher[response]{
+				{chanassert.MatchStruct(response{200, "ok"})},
+			},
+			messages: []response{{404, "not found"}},
+			expected: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.summary, func(t *testing.T) {
+			t.Parallel()
+
+			ch := make(chan response, len(test.messages))
+			exp := chanassert.NewChannelExpecter(ch)
+			for _, matchers := range test.layers {
+				exp.ExpectTimeout(time.Second, chanassert.ExactlyNOf(1, matchers...))
+			}
+
+			exp.Listen()
+			for _, m := range test.messages {
+				ch <- m
+			}
+
+			var nearMisses []string
+			for _, err := range exp.AwaitSatisfied(time.Millisecond * 100) {
+				var rejectErr chanassert.RejectionError[response]
+				if errors.As(err, &rejectErr) && rejectErr.NearMiss != nil {
+					nearMisses = append(nearMisses, rejectErr.NearMiss.String())
+				}
+			}
+
+			if actual := strings.Join(nearMisses, "\n"); actual != test.expected {
+				t.Errorf("expected near miss %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_NearMiss_AssertSatisfied(t *testing.T) {
+	ch := make(chan string, 10)
+	exp := chanassert.NewChannelExpecter(ch).
+		Expect(chanassert.OneOf(chanassert.MatchEqual("hello"))).
+		Expect(chanassert.OneOf(chanassert.MatchEqual("world"))).Named("greeting")
+
+	exp.Listen()
+	ch <- "world"
+
+	mock := &mockTestingT{}
+	exp.AssertSatisfied(mock, time.Millisecond*100)
+
+	expected := `Near miss: would have matched layer 'greeting', combiner #0, matcher #0 (equals "world"): did it arrive too early?`
+	for _, seen := range mock.seen {
+		if strings.Contains(seen.message, expected) {
+			return
+		}
+	}
+
+	t.Errorf("expected AssertSatisfied to report near miss %q, but saw: %v", expected, mock.seen)
+}
+
+func Test_NearMiss_Captures(t *testing.T) {
+	id := func(message string) any { return strings.Split(message, ":")[1] }
+
+	ch := make(chan string, 10)
+	exp := chanassert.NewChannelExpecter(ch).
+		Expect(chanassert.ExactlyNOf(2, chanassert.Capture("id", id, chanassert.MatchStringContains("id:")))).
+		Expect(chanassert.OneOf(chanassert.MatchCaptured("id", id)))
+
+	exp.Listen()
+	for _, m := range []string{"id:1", "ack:1", "id:2", "ack:2"} {
+		ch <- m
+	}
+
+	// The near miss is found using the value captured when "ack:1" was rejected, not the final value
+	expected := `would have matched layer #1, combiner #0, matcher #0 (equals captured "id"): did it arrive too early?`
+	for _, err := range exp.AwaitSatisfied(time.Millisecond * 100) {
+		var rejectErr chanassert.RejectionError[string]
+		if errors.As(err, &rejectErr) {
+			if rejectErr.NearMiss == nil || rejectErr.NearMiss.String() != expected {
+				t.Errorf("expected near miss %q, got %v", expected, rejectErr.NearMiss)
+			}
+
+			return
+		}
+	}
+
+	t.Errorf("expected \"ack:1\" to be rejected")
+}
+
+func Test_NearMiss_SkipsPredicates(t *testing.T) {
+	calls := 0
+	predicate := func(message string) bool {
+		calls++
+		return message == "world"
+	}
+
+	ch := make(chan string, 10)
+	exp := chanassert.NewChannelExpecter(ch).
+		Expect(chanassert.OneOf(chanassert.Capture("id", func(string) any { return 1 }, chanassert.MatchEqual("hello")))).
+		Expect(chanassert.OneOf(chanassert.MatchEqual("bye"))).
+		Expect(chanassert.OneOf(chanassert.MatchNot(chanassert.MatchPredicate(predicate)))).
+		Expect(chanassert.OneOf(chanassert.MatchCapturedPredicate("id", func(string, any) bool {
+			calls++
+			return true
+		})))
+
+	exp.Listen()
+	ch <- "hello"
+	ch <- "world"
+
+	for _, err := range exp.AwaitSatisfied(time.Millisecond * 100) {
+		var rejectErr chanassert.RejectionError[string]
+		if errors.As(err, &rejectErr) && rejectErr.NearMiss != nil {
+			t.Errorf("expected no near miss, got %q", rejectErr.NearMiss)
+		}
+	}
+
+	if calls != 0 {
+		t.Errorf("expected predicates of inactive layers not to be called, but they were called %d time(s)", calls)
+	}
+}
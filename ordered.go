@@ -0,0 +1,202 @@
+package chanassert
+
+import (
+	"fmt"
+	"math"
+)
+
+// InOrder accepts a list of matchers. The returned combiner will become
+// satisfied once each of the matchers have matched exactly one message, in the
+// order the matchers were provided. A message which matches a matcher out of order
+// is rejected by the combiner (and so may still be matched by another combiner in the layer).
+func InOrder[T any](matchers ...Matcher[T]) *orderedCombiner[T] {
+	return ExactlyNInOrder(1, matchers...)
+}
+
+// AtLeastNInOrder accepts a number, n, and a list of matchers. The returned combiner
+// will become satisfied once each of the matchers have matched AT LEAST N messages, in
+// the order the matchers were provided. Once a matcher has matched N messages, the combiner
+// will move on to the next matcher as soon as a message matches it.
+func AtLeastNInOrder[T any](n int, matchers ...Matcher[T]) *orderedCombiner[T] {
+	return &orderedCombiner[T]{matchers: matchers, min: n, max: math.MaxInt, counts: make([]int, len(matchers))}
+}
+
+// BetweenNInOrder accepts two numbers, min and max, and a list of matchers. The returned
+// combiner will become satisfied once each of the matchers have matched AT LEAST MIN and
+// NO MORE THAN MAX messages, in the order the matchers were provided.
+func BetweenNInOrder[T any](min int, max int, matchers ...Matcher[T]) *orderedCombiner[T] {
+	return &orderedCombiner[T]{matchers: matchers, min: min, max: max, counts: make([]int, len(matchers))}
+}
+
+// ExactlyNInOrder accepts a number, n, and a list of matchers. The returned combiner
+// will become satisfied once each of the matchers have matched EXACTLY N messages, in
+// the order the matchers were provided.
+func ExactlyNInOrder[T any](n int, matchers ...Matcher[T]) *orderedCombiner[T] {
+	return &orderedCombiner[T]{matchers: matchers, min: n, max: n, counts: make([]int, len(matchers))}
+}
+
+type orderedCombiner[T any] struct {
+	CombinerBase
+
+	matchers []Matcher[T]
+	min      int
+	max      int
+	counts   []int
+
+	// position is the index of the matcher which most recently
+	// matched a message (or zero, if no messages have been matched).
+	position int
+}
+
+func (ordered *orderedCombiner[T]) tryMatch(message T, scope *captureScope) (bool, TraceMessage) {
+	if ordered.saturated {
+		return false, newInfoTrace("Combiner is fully saturated, accepting no further messages")
+	}
+
+	attempts := make([]TraceMessage, 0)
+	for i, m := range ordered.matchers[:ordered.position] {
+		if matchWithCaptures(m, message, scope) {
+			attempts = append(attempts, newErrorTrace(fmt.Sprintf("%s REJECT: matcher matched but has already been passed (now on #%d)", matcherLabel(i, m, scope.formatter()), ordered.position)))
+		} else {
+			attempts = append(attempts, newErrorTrace(fmt.Sprintf("%s REJECT: no match", matcherLabel(i, m, scope.formatter()))))
+		}
+	}
+
+	// The message may match the current matcher, or any of the following matchers
+	// as long as the matchers before it have matched their minimum.
+	pending := ordered.position
+	for pending < len(ordered.matchers) && ordered.counts[pending] >= ordered.min {
+		pending++
+	}
+
+	for i := ordered.position; i < len(ordered.matchers); i++ {
+		m := ordered.matchers[i]
+		if i > ordered.position && ordered.counts[i-1] < ordered.min {
+			if matchWithCaptures(m, message, scope) {
+				attempts = append(attempts, newErrorTrace(fmt.Sprintf("%s REJECT: matcher matched but #%d still pending", matcherLabel(i, m, scope.formatter()), pending)))
+			} else {
+				attempts = append(attempts, newErrorTrace(fmt.Sprintf("%s REJECT: no match", matcherLabel(i, m, scope.formatter()))))
+			}
+			continue
+		}
+
+		if ok, reason := explainMatch(m, message, scope); !ok {
+			attempts = append(attempts, newErrorTrace(fmt.Sprintf("%s REJECT: %s", matcherLabel(i, m, scope.formatter()), reason)))
+			continue
+		}
+
+		if ordered.counts[i] >= ordered.max {
+			attempts = append(attempts, newErrorTrace(fmt.Sprintf("%s REJECT: matcher has already matched maximum allowed messages", matcherLabel(i, m, scope.formatter()))))
+			continue
+		}
+
+		ordered.position = i
+		ordered.counts[i]++
+		accepted := newInfoTrace(fmt.Sprintf("%s ACCEPT", matcherLabel(i, m, scope.formatter())))
+		if captured := captureMatched(m, message, scope); len(captured) > 0 {
+			accepted.Nested = append(accepted.Nested, newInfoTrace(fmt.Sprintf("Captured %s", captured)))
+		}
+
+		attempts = append(attempts, accepted)
+		return true, newInfoTrace(fmt.Sprintf("Combiner matched on matcher #%d", i), attempts...)
+	}
+
+	return false, newErrorTrace("Combiner failed match message", attempts...)
+}
+
+// TryMatch attempts to match the given message against the matcher which is
+// next in order. If a match is made, the returned bool will be true. A TraceMessage
+// is returned alongside (regardless of the match being successful or not) which explains
+// how the message was handled, including any matchers which matched out of order.
+func (ordered *orderedCombiner[T]) TryMatch(message T) (bool, TraceMessage) {
+	return ordered.tryMatchCaptures(message, nil)
+}
+
+func (ordered *orderedCombiner[T]) tryMatchCaptures(message T, scope *captureScope) (bool, TraceMessage) {
+	ok, trace := ordered.tryMatch(message, scope)
+
+	mode := fmt.Sprintf("IN ORDER mode with minimum of %d and maximum of %d", ordered.min, ordered.max)
+	satisfiedTrace := ordered.updateSatisfied()
+	saturatedTrace := ordered.updateSaturation()
+
+	status := NewCombinerStatusTrace(mode, satisfiedTrace, saturatedTrace, NewMatcherCountsTrace(ordered.counts))
+
+	trace.Nested = append(trace.Nested, status)
+	return ok, trace
+}
+
+func (ordered *orderedCombiner[T]) updateSatisfied() TraceMessage {
+	pending := make(idxList, 0)
+	for idx, c := range ordered.counts {
+		if c < ordered.min {
+			pending = append(pending, idx)
+		}
+	}
+
+	ordered.satisfied = len(pending) == 0
+	if ordered.satisfied {
+		return newSatisfiedTrace(true, fmt.Sprintf("EACH matcher has matched at least %d messages, in order", ordered.min))
+	}
+
+	return newSatisfiedTrace(false, fmt.Sprintf("EACH matcher needs to match at least %d messages in order, but matchers %v have not", ordered.min, pending))
+}
+
+func (ordered *orderedCombiner[T]) updateSaturation() TraceMessage {
+	last := len(ordered.matchers) - 1
+	ordered.saturated = last < 0 || (ordered.position == last && ordered.counts[last] >= ordered.max)
+	if ordered.saturated {
+		return newSaturatedTrace(true, fmt.Sprintf("Final matcher #%d has matched maximum allowed messages (%d)", last, ordered.max))
+	}
+
+	return newSaturatedTrace(false, fmt.Sprintf("Final matcher #%d needs to match %d messages, currently on matcher #%d", last, ordered.max, ordered.position))
+}
+
+func (ordered *orderedCombiner[T]) report(values *valueFormatter) CombinerReport {
+	report := CombinerReport{
+		Description: ordered.summary(),
+		Mode:        "IN ORDER",
+		Min:         ordered.min,
+		Max:         ordered.max,
+		Matchers:    reportMatchers(ordered.matchers, values, func(idx int) int { return ordered.counts[idx] }),
+	}
+
+	for idx, m := range ordered.matchers {
+		if c := ordered.counts[idx]; c < ordered.min {
+			report.Shortfall = append(report.Shortfall, fmt.Sprintf("needs %d more of %s, in order", ordered.min-c, label("matcher", idx, m)))
+		}
+	}
+
+	return report
+}
+
+// Reset discards all messages matched by this combiner, allowing it to be
+// used by another layer once the expecter using it has finished.
+func (ordered *orderedCombiner[T]) Reset() {
+	ordered.counts = make([]int, len(ordered.matchers))
+	ordered.position = 0
+	ordered.ResetBase()
+}
+
+// Named names this combiner, so that it is referred to by name (rather
+// than by its index) in traces and errors.
+func (ordered *orderedCombiner[T]) Named(name string) *orderedCombiner[T] {
+	ordered.SetName(name)
+	return ordered
+}
+
+// Describe describes the bounds of this combiner, and each of its matchers (in order).
+func (ordered *orderedCombiner[T]) Describe() string {
+	return ordered.describeWith(defaultValueFormatter)
+}
+
+func (ordered *orderedCombiner[T]) describeWith(values *valueFormatter) string {
+	return describeList(ordered.summary(), describeMatchers(ordered.matchers, values))
+}
+
+func (ordered *orderedCombiner[T]) describeTree(values *valueFormatter) TraceMessage {
+	return newInfoTrace(ordered.summary(), describeMatcherTree(ordered.matchers, values)...)
+}
+
+func (ordered *orderedCombiner[T]) summary() string {
+	return fmt.Sprintf("%s message(s) matching each of, in order", describeBounds(ordered.min, ordered.max))
+}
@@ -0,0 +1,196 @@
+package chanassert_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hbomb79/go-chanassert"
+)
+
+func Test_InOrder(t *testing.T) {
+	makeCombiner := func() chanassert.Combiner[string] {
+		return chanassert.InOrder(
+			chanassert.MatchEqual("a"),
+			chanassert.MatchEqual("b"),
+			chanassert.MatchEqual("c"),
+		)
+	}
+
+	tests := []combinerTest[string]{
+		{
+			summary:   "Messages in order",
+			messages:  []string{"a", "b", "c"},
+			expected:  []bool{true, true, true},
+			satisfied: []bool{false, false, true},
+		},
+		{
+			summary:   "Messages out of order",
+			messages:  []string{"b", "a", "c", "b", "c"},
+			expected:  []bool{false, true, false, true, true},
+			satisfied: []bool{false, false, false, false, true},
+		},
+		{
+			summary:   "Repeated messages",
+			messages:  []string{"a", "a", "b", "a", "c", "c"},
+			expected:  []bool{true, false, true, false, true, false},
+			satisfied: []bool{false, false, false, false, true, true},
+		},
+		{
+			summary:   "Unknown messages",
+			messages:  []string{"a", "foo", "b", "c"},
+			expected:  []bool{true, false, true, true},
+			satisfied: []bool{false, false, false, true},
+		},
+	}
+
+	runCombinerTests(t, makeCombiner, tests)
+}
+
+func Test_AtLeastNInOrder(t *testing.T) {
+	makeCombiner := func() chanassert.Combiner[string] {
+		return chanassert.AtLeastNInOrder(2,
+			chanassert.MatchEqual("a"),
+			chanassert.MatchEqual("b"),
+		)
+	}
+
+	tests := []combinerTest[string]{
+		{
+			summary:   "Exactly N messages in order",
+			messages:  []string{"a", "a", "b", "b"},
+			expected:  []bool{true, true, true, true},
+			satisfied: []bool{false, false, false, true},
+		},
+		{
+			summary:   "More than N messages in order",
+			messages:  []string{"a", "a", "a", "b", "b", "b"},
+			expected:  []bool{true, true, true, true, true, true},
+			satisfied: []bool{false, false, false, false, true, true},
+		},
+		{
+			summary:   "Moved on before N messages",
+			messages:  []string{"a", "b", "a", "b", "b"},
+			expected:  []bool{true, false, true, true, true},
+			satisfied: []bool{false, false, false, false, true},
+		},
+		{
+			summary:   "Earlier matcher after moving on",
+			messages:  []string{"a", "a", "b", "a"},
+			expected:  []bool{true, true, true, false},
+			satisfied: []bool{false, false, false, false},
+		},
+	}
+
+	runCombinerTests(t, makeCombiner, tests)
+}
+
+func Test_BetweenNInOrder(t *testing.T) {
+	makeCombiner := func() chanassert.Combiner[string] {
+		return chanassert.BetweenNInOrder(1, 2,
+			chanassert.MatchEqual("a"),
+			chanassert.MatchEqual("b"),
+		)
+	}
+
+	tests := []combinerTest[string]{
+		{
+			summary:   "Minimum messages in order",
+			messages:  []string{"a", "b"},
+			expected:  []bool{true, true},
+			satisfied: []bool{false, true},
+		},
+		{
+			summary:   "Maximum messages in order",
+			messages:  []string{"a", "a", "b", "b", "b"},
+			expected:  []bool{true, true, true, true, false},
+			satisfied: []bool{false, false, true, true, true},
+		},
+		{
+			summary:   "Too many messages for first matcher",
+			messages:  []string{"a", "a", "a", "b"},
+			expected:  []bool{true, true, false, true},
+			satisfied: []bool{false, false, false, true},
+		},
+	}
+
+	runCombinerTests(t, makeCombiner, tests)
+}
+
+func Test_ExactlyNInOrder(t *testing.T) {
+	makeCombiner := func() chanassert.Combiner[string] {
+		return chanassert.ExactlyNInOrder(2,
+			chanassert.MatchEqual("a"),
+			chanassert.MatchEqual("b"),
+		)
+	}
+
+	tests := []combinerTest[string]{
+		{
+			summary:   "Exactly N messages in order",
+			messages:  []string{"a", "a", "b", "b", "b"},
+			expected:  []bool{true, true, true, true, false},
+			satisfied: []bool{false, false, false, true, true},
+		},
+		{
+			summary:   "Too few messages for first matcher",
+			messages:  []string{"a", "b", "a", "b", "b"},
+			expected:  []bool{true, false, true, true, true},
+			satisfied: []bool{false, false, false, false, true},
+		},
+	}
+
+	runCombinerTests(t, makeCombiner, tests)
+}
+
+func Test_InOrder_Trace(t *testing.T) {
+	combiner := chanassert.InOrder(
+		chanassert.MatchEqual("a"),
+		chanassert.MatchEqual("b"),
+		chanassert.MatchEqual("c"),
+	)
+
+	assertTraceContains := func(message string, expected string) {
+		_, trace := combiner.TryMatch(message)
+		builder := &strings.Builder{}
+		trace.PrintTrace(builder, true, 0)
+		if !strings.Contains(builder.String(), expected) {
+			t.Errorf("expected trace for message %q to contain %q, but it did not:\n%s", message, expected, builder.String())
+		}
+	}
+
+	assertTraceContains("b", `Matcher #1 (equals "b") REJECT: matcher matched but #0 still pending`)
+	assertTraceContains("a", `Matcher #0 (equals "a") ACCEPT`)
+	assertTraceContains("c", `Matcher #2 (equals "c") REJECT: matcher matched but #1 still pending`)
+	assertTraceContains("b", `Matcher #1 (equals "b") ACCEPT`)
+	assertTraceContains("a", `Matcher #0 (equals "a") REJECT: matcher matched but has already been passed (now on #1)`)
+}
+
+func Test_InOrder_Interleaved(t *testing.T) {
+	makeExpecter := func() (chan string, chanassert.Expecter[string]) {
+		ch := make(chan string, 10)
+		exp := chanassert.NewChannelExpecter(ch).Expect(
+			chanassert.InOrder(
+				chanassert.MatchEqual("a"), chanassert.MatchEqual("b"), chanassert.MatchEqual("c"),
+			),
+			chanassert.AllOf(
+				chanassert.MatchEqual("x"), chanassert.MatchEqual("y"),
+			),
+		)
+
+		return ch, exp
+	}
+
+	tests := []expecterTest[string]{
+		{
+			Summary:  "Ordered messages interleaved with unordered",
+			Messages: []string{"y", "a", "x", "b", "c"},
+		},
+		{
+			Summary:        "Ordered messages out of order",
+			Messages:       []string{"a", "x", "c", "y", "b", "c"},
+			ExpectedErrors: []expectedError{rejectedError},
+		},
+	}
+
+	runExpecterTests(t, makeExpecter, tests)
+}
@@ -0,0 +1,260 @@
+package chanassert
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// TraceEntry is the trace of a single message processed by an expecter, as provided to a [TraceRenderer].
+type TraceEntry struct {
+	// MessageNum is the position of the message in the order the expecter received them.
+	MessageNum int
+
+	// Message is the message, formatted by the formatter of the expecter (see [Expecter.FormatMessages]).
+	Message   string
+	LayerIdx  int
+	LayerName string
+	Status    MessageStatus
+
+	// Trace is the trace of the message, excluding any trace messages which are
+	// more verbose than the trace is being printed with (see [TraceLevel]).
+	Trace    TraceMessage
+	Captures Captures
+}
+
+// header returns the line which introduces the trace of the message (e.g. "Message 'a' - ACCEPTED").
+func (entry TraceEntry) header() string {
+	if len(entry.Captures) > 0 {
+		return fmt.Sprintf("Message '%s' - %s (captured %s)", entry.Message, entry.Status, entry.Captures)
+	}
+
+	return fmt.Sprintf("Message '%s' - %s", entry.Message, entry.Status)
+}
+
+// TraceRenderer renders the trace of the messages processed by an expecter. The renderer used by
+// [Expecter.FPrintTrace], [Expecter.PrintTrace] and [Expecter.AssertSatisfied] can be chosen using [WithRenderer].
+type TraceRenderer interface {
+	RenderTrace(w io.Writer, entry TraceEntry)
+}
+
+type traceConfig struct {
+	renderer  TraceRenderer
+	verbosity *TraceLevel
+
+	// filters select which messages are included in the trace. The result
+	// provided is the MessageResult of the message, and entry its TraceEntry.
+	filters []func(result any, entry TraceEntry) bool
+	// htmlDir is the directory AssertSatisfied writes an HTML report to on failure, if any.
+	htmlDir string
+}
+
+// includes returns true if the message passes all of the filters.
+func (config traceConfig) includes(result any, entry TraceEntry) bool {
+	for _, filter := range config.filters {
+		if !filter(result, entry) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// TraceOption configures how the trace of an expecter is printed.
+type TraceOption func(*traceConfig)
+
+// WithRenderer prints the trace using the renderer provided, rather than [IndentRenderer].
+func WithRenderer(renderer TraceRenderer) TraceOption {
+	return func(config *traceConfig) {
+		config.renderer = renderer
+	}
+}
+
+// WithVerbosity prints the trace with the verbosity provided, rather than the verbosity
+// of the expecter (see [Expecter.Verbosity] and [Expecter.Debug]).
+func WithVerbosity(level TraceLevel) TraceOption {
+	return func(config *traceConfig) {
+		config.verbosity = &level
+	}
+}
+
+// OnlyStatus only prints the trace of messages with one of the statuses provided (e.g. only [Rejected] messages).
+func OnlyStatus(statuses ...MessageStatus) TraceOption {
+	return func(config *traceConfig) {
+		config.filters = append(config.filters, func(_ any, entry TraceEntry) bool {
+			return slices.Contains(statuses, entry.Status)
+		})
+	}
+}
+
+// OnlyLayers only prints the trace of messages which were processed by one of the layers at
+// the indices provided. Ignored messages are not processed by any layer, and so are not printed.
+func OnlyLayers(layerIdxs ...int) TraceOption {
+	return func(config *traceConfig) {
+		config.filters = append(config.filters, func(_ any, entry TraceEntry) bool {
+			return entry.LayerIdx >= 0 && slices.Contains(layerIdxs, entry.LayerIdx)
+		})
+	}
+}
+
+// OnlyMessages only prints the trace of the messages numbered from (inclusive) up to to (exclusive),
+// where messages are numbered from zero in the order they were received by the expecter.
+func OnlyMessages(from int, to int) TraceOption {
+	return func(config *traceConfig) {
+		config.filters = append(config.filters, func(_ any, entry TraceEntry) bool {
+			return entry.MessageNum >= from && entry.MessageNum < to
+		})
+	}
+}
+
+// FilterResults only prints the trace of the messages for which the predicate returns true. The
+// filter only applies to expecters of the same message type as the predicate, and is skipped when
+// printing the trace of an expecter of any other type (as the predicate cannot be called).
+func FilterResults[T any](predicate func(result MessageResult[T]) bool) TraceOption {
+	return func(config *traceConfig) {
+		config.filters = append(config.filters, func(result any, _ TraceEntry) bool {
+			typed, ok := result.(MessageResult[T])
+			return !ok || predicate(typed)
+		})
+	}
+}
+
+func newTraceConfig(opts []TraceOption) traceConfig {
+	config := traceConfig{renderer: IndentRenderer()}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	return config
+}
+
+type indentRenderer struct{}
+
+// IndentRenderer renders the trace of each message as an indented list, using a different
+// prefix ('-', '*', '+' and '>') for each level of nesting. This is the default renderer.
+func IndentRenderer() *indentRenderer {
+	return &indentRenderer{}
+}
+
+func (indent *indentRenderer) RenderTrace(w io.Writer, entry TraceEntry) {
+	fmt.Fprintf(w, "%s:\n", entry.header())
+	entry.Trace.PrintTrace(w, true, 0)
+	fmt.Fprintln(w, "")
+}
+
+type treeRenderer struct{}
+
+// TreeRenderer renders the trace of each message as a tree, using box-drawing characters.
+func TreeRenderer() *treeRenderer {
+	return &treeRenderer{}
+}
+
+func (tree *treeRenderer) RenderTrace(w io.Writer, entry TraceEntry) {
+	fmt.Fprintln(w, entry.header())
+	tree.renderBranch(w, entry.Trace, "", true)
+	fmt.Fprintln(w, "")
+}
+
+func (tree *treeRenderer) renderBranch(w io.Writer, msg TraceMessage, indent string, last bool) {
+	branch, continuation := "├─ ", "│  "
+	if last {
+		branch, continuation = "└─ ", "   "
+	}
+
+	fmt.Fprintf(w, "%s%s%s\n", indent, branch, traceLine(msg))
+	for idx, nested := range msg.Nested {
+		tree.renderBranch(w, nested, indent+continuation, idx == len(msg.Nested)-1)
+	}
+}
+
+type compactRenderer struct{}
+
+// CompactRenderer renders a single line for each message, containing the status of the
+// message and the outermost line of its trace (e.g. which layer and combiner accepted it).
+func CompactRenderer() *compactRenderer {
+	return &compactRenderer{}
+}
+
+func (compact *compactRenderer) RenderTrace(w io.Writer, entry TraceEntry) {
+	summary := fmt.Sprintf("#%d %-8s '%s': %s", entry.MessageNum, entry.Status, entry.Message, entry.Trace.Message)
+	if len(entry.Captures) > 0 {
+		summary += fmt.Sprintf(" (captured %s)", entry.Captures)
+	}
+
+	fmt.Fprintln(w, summary)
+}
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+	ansiGrey  = "\x1b[90m"
+)
+
+var statusColors = map[MessageStatus]string{
+	Accepted: ansiGreen,
+	Rejected: ansiRed,
+	Ignored:  ansiGrey,
+}
+
+type colorRenderer struct {
+	renderer TraceRenderer
+	force    bool
+}
+
+// ColorRenderer colours the output of the renderer provided using ANSI escape codes, based on the
+// status of each message: accepted messages are green, rejected messages are red, and ignored messages
+// are grey. Colours are only used when writing to a terminal (and the NO_COLOR environment variable is
+// not set), unless forced using ForceColor.
+func ColorRenderer(renderer TraceRenderer) *colorRenderer {
+	return &colorRenderer{renderer: renderer}
+}
+
+// ForceColor enables colours regardless of whether the trace is being written to a terminal. This is
+// useful for AssertSatisfied, as the output of 'go test' is not typically written directly to a terminal.
+func (color *colorRenderer) ForceColor() *colorRenderer {
+	color.force = true
+	return color
+}
+
+func (color *colorRenderer) RenderTrace(w io.Writer, entry TraceEntry) {
+	if !color.force && !isTerminal(w) {
+		color.renderer.RenderTrace(w, entry)
+		return
+	}
+
+	builder := &strings.Builder{}
+	color.renderer.RenderTrace(builder, entry)
+
+	rendered := builder.String()
+	if rendered == "" {
+		return
+	}
+
+	// Each line is coloured separately, so that the
+	// colour is not lost if the output is split into lines
+	for _, line := range strings.Split(strings.TrimSuffix(rendered, "\n"), "\n") {
+		if line != "" {
+			fmt.Fprintf(w, "%s%s%s\n", statusColors[entry.Status], line, ansiReset)
+		} else {
+			fmt.Fprintln(w, "")
+		}
+	}
+}
+
+// isTerminal returns true if the writer is a terminal which colours should be written to.
+func isTerminal(w io.Writer) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,262 @@
+package chanassert_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hbomb79/go-chanassert"
+)
+
+func makeRenderedExpecter(t *testing.T) chanassert.Expecter[string] {
+	ch := make(chan string, 10)
+	exp := chanassert.NewChannelExpecter(ch).
+		Ignore(chanassert.MatchEqual("ping")).
+		Expect(chanassert.OneOf(chanassert.MatchEqual("hello")))
+
+	exp.Listen()
+	for _, m := range []string{"world", "ping", "hello"} {
+		ch <- m
+	}
+
+	if errs := exp.AwaitSatisfied(time.Second); len(errs) != 1 {
+		t.Fatalf("expected a single rejection, got %v", errs)
+	}
+
+	return exp
+}
+
+func Test_Renderers(t *testing.T) {
+	tests := []struct {
+		summary  string
+		renderer chanassert.TraceRenderer
+		expected string
+	}{
+		{
+			summary:  "Indent",
+			renderer: chanassert.IndentRenderer(),
+			expected: strings.Join([]string{
+				"Message 'world' - REJECTED:",
+				"  - Layer #0 could not match message against any combiners",
+				"    * Combiner #0: Combiner failed match message",
+				`      + Matcher #0 (equals "hello") REJECT: no match`,
+				"      + Combiner status",
+				"        > SUM mode with minimum of 1 and maximum of 1",
+				"        > NOT satisfied",
+				"        > NOT saturated",
+				"",
+			}, "\n"),
+		},
+		{
+			summary:  "Tree",
+			renderer: chanassert.TreeRenderer(),
+			expected: strings.Join([]string{
+				"Message 'world' - REJECTED",
+				"└─ Layer #0 could not match message against any combiners",
+				"   └─ Combiner #0: Combiner failed match message",
+				`      ├─ Matcher #0 (equals "hello") REJECT: no match`,
+				"      └─ Combiner status",
+				"         ├─ SUM mode with minimum of 1 and maximum of 1",
+				"         ├─ NOT satisfied",
+				"         └─ NOT saturated",
+				"",
+			}, "\n"),
+		},
+		{
+			summary:  "Compact",
+			renderer: chanassert.CompactRenderer(),
+			expected: strings.Join([]string{
+				"#0 REJECTED 'world': Layer #0 could not match message against any combiners",
+				"#1 IGNORED  'ping': Ignore matcher #0 (equals \"ping\") ACCEPTED",
+				"#2 ACCEPTED 'hello': Layer #0 matched message against combiner #0",
+			}, "\n"),
+		},
+		{
+			summary:  "Color without terminal",
+			renderer: chanassert.ColorRenderer(chanassert.CompactRenderer()),
+			expected: "#0 REJECTED 'world': Layer #0 could not match message against any combiners",
+		},
+		{
+			summary:  "Color forced",
+			renderer: chanassert.ColorRenderer(chanassert.CompactRenderer()).ForceColor(),
+			expected: strings.Join([]string{
+				"\x1b[31m#0 REJECTED 'world': Layer #0 could not match message against any combiners\x1b[0m",
+				"\x1b[90m#1 IGNORED  'ping': Ignore matcher #0 (equals \"ping\") ACCEPTED\x1b[0m",
+				"\x1b[32m#2 ACCEPTED 'hello': Layer #0 matched message against combiner #0\x1b[0m",
+			}, "\n"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.summary, func(t *testing.T) {
+			t.Parallel()
+
+			builder := &strings.Builder{}
+			makeRenderedExpecter(t).FPrintTrace(builder, chanassert.WithRenderer(test.renderer))
+			if !strings.HasPrefix(builder.String(), test.expected) {
+				t.Errorf("expected trace to begin with:\n%s\nbut got:\n%s", test.expected, builder.String())
+			}
+		})
+	}
+}
+
+func Test_ColorRenderer_LongLines(t *testing.T) {
+	// Lines longer than the buffer of a bufio.Scanner (64KiB) must not be dropped
+	long := strings.Repeat("x", 100*1024)
+	ch := make(chan string, 1)
+	exp := chanassert.NewChannelExpecter(ch).Expect(chanassert.OneOf(chanassert.MatchEqual(long)))
+	exp.Listen()
+	ch <- long
+	exp.AwaitSatisfied(time.Millisecond * 100)
+
+	builder := &strings.Builder{}
+	exp.FPrintTrace(builder, chanassert.WithRenderer(chanassert.ColorRenderer(chanassert.CompactRenderer()).ForceColor()))
+
+	expected := "\x1b[32m#0 ACCEPTED '" + long + "': Layer #0 matched message against combiner #0\x1b[0m\n"
+	if builder.String() != expected {
+		t.Errorf("expected long line to be coloured, got %d bytes: %.200q", builder.Len(), builder.String())
+	}
+}
+
+func Test_Renderers_AssertSatisfied(t *testing.T) {
+	ch := make(chan string, 10)
+	exp := chanassert.NewChannelExpecter(ch).Expect(chanassert.OneOf(chanassert.MatchEqual("hello")))
+	exp.Listen()
+	ch <- "world"
+
+	mock := &mockTestingT{}
+	exp.AssertSatisfied(mock, time.Millisecond*100, chanassert.WithRenderer(chanassert.CompactRenderer()))
+
+	expected := "#0 REJECTED 'world': Layer #0 could not match message against any combiners\n"
+	for _, seen := range mock.seen {
+		if strings.Contains(seen.message, expected) {
+			return
+		}
+	}
+
+	t.Errorf("expected AssertSatisfied to render rejection using the compact renderer, but saw: %v", mock.seen)
+}
+
+func Test_TraceVerbosity(t *testing.T) {
+	tests := []struct {
+		summary  string
+		level    chanassert.TraceLevel
+		expected []string
+		excluded []string
+	}{
+		{
+			summary:  "Error",
+			level:    chanassert.LevelError,
+			expected: []string{`      + Matcher #0 (equals "hello") REJECT: no match`, "  - Layer #0 matched message against combiner #0\n\n"},
+			excluded: []string{"Combiner status", "[DEBUG]"},
+		},
+		{
+			summary:  "Info",
+			level:    chanassert.LevelInfo,
+			expected: []string{"Combiner status", "        > NOT satisfied\n"},
+			excluded: []string{"[DEBUG]"},
+		},
+		{
+			summary:  "Debug",
+			level:    chanassert.LevelDebug,
+			expected: []string{"[DEBUG] Layer Status", "[DEBUG] SUM of all matched messages (0) must meet 1 messages"},
+			excluded: []string{"Matcher counts"},
+		},
+		{
+			summary:  "Trace",
+			level:    chanassert.LevelTrace,
+			expected: []string{"[DEBUG] Layer Status", "[DEBUG] Matcher counts", "          - Matcher #0 => 1 message(s)"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.summary, func(t *testing.T) {
+			t.Parallel()
+
+			check := func(trace string) {
+				for _, expected := range test.expected {
+					if !strings.Contains(trace, expected) {
+						t.Errorf("expected trace to contain %q, but got:\n%s", expected, trace)
+					}
+				}
+
+				for _, excluded := range test.excluded {
+					if strings.Contains(trace, excluded) {
+						t.Errorf("expected trace NOT to contain %q, but got:\n%s", excluded, trace)
+					}
+				}
+			}
+
+			// The verbosity can be set on the expecter, or when printing the trace
+			builder := &strings.Builder{}
+			makeRenderedExpecter(t).Verbosity(test.level).FPrintTrace(builder)
+			check(builder.String())
+
+			builder = &strings.Builder{}
+			makeRenderedExpecter(t).Debug().FPrintTrace(builder, chanassert.WithVerbosity(test.level))
+			check(builder.String())
+		})
+	}
+}
+
+func Test_TraceFilters(t *testing.T) {
+	tests := []struct {
+		summary  string
+		opts     []chanassert.TraceOption
+		expected []string
+	}{
+		{
+			summary:  "Status",
+			opts:     []chanassert.TraceOption{chanassert.OnlyStatus(chanassert.Rejected, chanassert.Ignored)},
+			expected: []string{"world", "ping"},
+		},
+		{
+			summary:  "Layer",
+			opts:     []chanassert.TraceOption{chanassert.OnlyLayers(0)},
+			expected: []string{"world", "hello"},
+		},
+		{
+			summary:  "Message range",
+			opts:     []chanassert.TraceOption{chanassert.OnlyMessages(1, 3)},
+			expected: []string{"ping", "hello"},
+		},
+		{
+			summary: "Predicate",
+			opts: []chanassert.TraceOption{chanassert.FilterResults(func(result chanassert.MessageResult[string]) bool {
+				return strings.HasPrefix(result.Message, "h")
+			})},
+			expected: []string{"hello"},
+		},
+		{
+			summary: "Predicate of another type is skipped",
+			opts: []chanassert.TraceOption{chanassert.FilterResults(func(chanassert.MessageResult[int]) bool {
+				return false
+			})},
+			expected: []string{"world", "ping", "hello"},
+		},
+		{
+			summary:  "Combined",
+			opts:     []chanassert.TraceOption{chanassert.OnlyLayers(0), chanassert.OnlyMessages(1, 3)},
+			expected: []string{"hello"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.summary, func(t *testing.T) {
+			t.Parallel()
+
+			builder := &strings.Builder{}
+			opts := append(test.opts, chanassert.WithRenderer(chanassert.CompactRenderer()))
+			makeRenderedExpecter(t).FPrintTrace(builder, opts...)
+
+			var messages []string
+			for _, line := range strings.Split(strings.TrimSpace(builder.String()), "\n") {
+				messages = append(messages, strings.Split(line, "'")[1])
+			}
+
+			if strings.Join(messages, ", ") != strings.Join(test.expected, ", ") {
+				t.Errorf("expected trace of messages %v, but got:\n%s", test.expected, builder.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package chanassert
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LayerReport is a structured breakdown of the state of a layer, and each of its combiners. It
+// is attached to an [UnsatisfiedError] in order to explain why the layer did not become satisfied,
+// without needing to enable Debug mode and read through the entire trace.
+type LayerReport struct {
+	// Label is how the layer is referred to in traces (e.g. "Layer #0" or "Layer 'handshake'").
+	Label       string    `json:"label"`
+	Description string    `json:"description"`
+	Mode        LayerMode `json:"mode"`
+
+	// Quorum is the number of combiners which must be satisfied, for layers in [ModeQuorum].
+	Quorum int `json:"quorum,omitempty"`
+
+	Combiners []CombinerReport `json:"combiners,omitempty"`
+
+	// Shortfall describes what the layer still needs in order to become satisfied
+	// (e.g. "needs combiner #1 to be satisfied").
+	Shortfall []string `json:"shortfall,omitempty"`
+}
+
+// CombinerReport is a structured breakdown of the state of a combiner. Combiners which
+// are not built-in only report their label, description, and satisfied/saturated flags.
+type CombinerReport struct {
+	// Label is how the combiner is referred to in traces (e.g. "Combiner #0" or "Combiner 'auth events'").
+	Label       string `json:"label"`
+	Description string `json:"description"`
+
+	// Mode is the mode of the combiner (e.g. "EACH", "SUM" or "IN ORDER"), and Min/Max are
+	// its bounds. For composite combiners, Mode is either "AND" or "OR", and the bounds are zero.
+	Mode string `json:"mode"`
+	Min  int    `json:"min"`
+	Max  int    `json:"max"`
+
+	Satisfied bool `json:"satisfied"`
+	Saturated bool `json:"saturated"`
+	Optional  bool `json:"optional"`
+
+	// Matchers holds the number of messages matched by each matcher, and Keys the number of messages
+	// matched for each key (for keyed combiners, such as [ExactlyNOfEachKey]), in the order first seen.
+	Matchers []MatcherReport `json:"matchers,omitempty"`
+	Keys     []KeyReport     `json:"keys,omitempty"`
+
+	// Combiners holds the reports of the combiners contained within a composite combiner.
+	Combiners []CombinerReport `json:"combiners,omitempty"`
+
+	// Shortfall describes what the combiner still needs in order to become
+	// satisfied (e.g. "needs 2 more of matcher #1"). Empty once satisfied.
+	Shortfall []string `json:"shortfall,omitempty"`
+}
+
+// MatcherReport holds the number of messages matched by a matcher within a combiner.
+type MatcherReport struct {
+	Label       string `json:"label"`
+	Description string `json:"description"`
+	Count       int    `json:"count"`
+}
+
+// KeyReport holds the number of messages matched for a key within a keyed combiner.
+type KeyReport struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// layerReporter is implemented by layers which are able to report their state.
+type layerReporter interface {
+	report(values *valueFormatter) LayerReport
+}
+
+// combinerReporter is implemented by combiners which are able to report their state, beyond
+// whether they are satisfied or saturated. The label and flags are populated by reportCombiner.
+type combinerReporter interface {
+	report(values *valueFormatter) CombinerReport
+}
+
+// reportCombiner returns the report for the combiner at the index provided.
+func reportCombiner[T any](idx int, combiner Combiner[T], values *valueFormatter) CombinerReport {
+	report := CombinerReport{Description: describeWith(combiner, values)}
+	if reporter, ok := combiner.(combinerReporter); ok {
+		report = reporter.report(values)
+	}
+
+	report.Label = label("Combiner", idx, combiner)
+	report.Satisfied = combiner.IsSatisfied()
+	report.Saturated = isSaturated(combiner)
+	report.Optional = isOptional(combiner)
+	if report.Satisfied {
+		report.Shortfall = nil
+	}
+
+	return report
+}
+
+func reportCombiners[T any](combiners []Combiner[T], values *valueFormatter) []CombinerReport {
+	reports := make([]CombinerReport, 0, len(combiners))
+	for idx, c := range combiners {
+		reports = append(reports, reportCombiner(idx, c, values))
+	}
+
+	return reports
+}
+
+func reportMatchers[T any](matchers []Matcher[T], values *valueFormatter, count func(idx int) int) []MatcherReport {
+	reports := make([]MatcherReport, 0, len(matchers))
+	for idx, m := range matchers {
+		reports = append(reports, MatcherReport{Label: label("Matcher", idx, m), Description: describeWith(m, values), Count: count(idx)})
+	}
+
+	return reports
+}
+
+// unsatisfiedCombiners returns the shortfall of a layer (or composite combiner)
+// which needs ALL of the non-optional combiners provided to be satisfied.
+func unsatisfiedCombiners[T any](combiners []Combiner[T]) []string {
+	_, notSatisfied, _ := partitionCombiners(combiners)
+
+	shortfall := make([]string, 0, len(notSatisfied))
+	for _, idx := range notSatisfied {
+		shortfall = append(shortfall, fmt.Sprintf("needs %s to be satisfied", label("combiner", idx, combiners[idx])))
+	}
+
+	return shortfall
+}
+
+func (report LayerReport) trace() TraceMessage {
+	nested := make([]TraceMessage, 0, len(report.Combiners)+len(report.Shortfall))
+	for _, combiner := range report.Combiners {
+		nested = append(nested, combiner.trace())
+	}
+
+	nested = append(nested, shortfallTraces(report.Shortfall)...)
+	return newInfoTrace(fmt.Sprintf("%s: %s", report.Label, report.Description), nested...)
+}
+
+func (report CombinerReport) trace() TraceMessage {
+	nested := make([]TraceMessage, 0, len(report.Matchers)+len(report.Keys)+len(report.Combiners)+len(report.Shortfall))
+	for _, matcher := range report.Matchers {
+		nested = append(nested, newInfoTrace(fmt.Sprintf("%s: %s => %s", matcher.Label, matcher.Description, formatCount(matcher.Count))))
+	}
+
+	for _, key := range report.Keys {
+		nested = append(nested, newInfoTrace(fmt.Sprintf("Key %s => %s", key.Key, formatCount(key.Count))))
+	}
+
+	for _, combiner := range report.Combiners {
+		nested = append(nested, combiner.trace())
+	}
+
+	nested = append(nested, shortfallTraces(report.Shortfall)...)
+	return newInfoTrace(fmt.Sprintf("%s: %s (%s)", report.Label, report.Description, report.status()), nested...)
+}
+
+func (report CombinerReport) status() string {
+	status := make([]string, 0, 3)
+	if report.Optional {
+		status = append(status, "optional")
+	}
+
+	if report.Satisfied {
+		status = append(status, "satisfied")
+	} else {
+		status = append(status, "NOT satisfied")
+	}
+
+	if report.Saturated {
+		status = append(status, "saturated")
+	} else {
+		status = append(status, "NOT saturated")
+	}
+
+	return strings.Join(status, ", ")
+}
+
+func shortfallTraces(shortfall []string) []TraceMessage {
+	traces := make([]TraceMessage, 0, len(shortfall))
+	for _, s := range shortfall {
+		traces = append(traces, newInfoTrace("Shortfall: "+s))
+	}
+
+	return traces
+}
+
+func formatCount(count int) string {
+	if count > 0 {
+		return fmt.Sprintf("%d message(s)", count)
+	}
+
+	return "0 messages"
+}
@@ -0,0 +1,126 @@
+package chanassert_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hbomb79/go-chanassert"
+)
+
+// awaitUnsatisfied delivers the messages to the expecter, and returns
+// the UnsatisfiedError reported once it has been terminated.
+func awaitUnsatisfied(t *testing.T, exp chanassert.Expecter[string], ch chan string, messages ...string) chanassert.UnsatisfiedError {
+	exp.Listen()
+	for _, m := range messages {
+		ch <- m
+	}
+
+	for _, err := range exp.AwaitSatisfied(time.Millisecond * 100) {
+		var unsatisfiedErr chanassert.UnsatisfiedError
+		if errors.As(err, &unsatisfiedErr) {
+			return unsatisfiedErr
+		}
+	}
+
+	t.Fatalf("expected expecter to report an UnsatisfiedError")
+	return chanassert.UnsatisfiedError{}
+}
+
+func Test_UnsatisfiedReport(t *testing.T) {
+	ch := make(chan string, 10)
+	exp := chanassert.NewChannelExpecter(ch).Expect(
+		chanassert.ExactlyNOfEach(3, chanassert.MatchEqual("a"), chanassert.MatchEqual("b")).Named("pairs"),
+		chanassert.AtLeastNOfEachKey(1, workerKey, chanassert.MatchStringContains(":ready")).WithKeys("w1", "w2"),
+		chanassert.AnyCombiner[string](
+			chanassert.InOrder(chanassert.MatchEqual("x"), chanassert.MatchEqual("y")),
+			chanassert.OneOf(chanassert.MatchEqual("z")),
+		),
+		chanassert.Optional[string](chanassert.OneOf(chanassert.MatchEqual("maybe"))),
+	)
+
+	report := awaitUnsatisfied(t, exp, ch, "a", "b", "a", "w1:ready", "x").Report
+	if report == nil {
+		t.Fatalf("expected UnsatisfiedError to carry a report")
+	}
+
+	if report.Label != "Layer #0" || report.Mode != chanassert.ModeAnd || len(report.Combiners) != 4 {
+		t.Fatalf("unexpected layer report: %+v", report)
+	}
+
+	pairs := report.Combiners[0]
+	if pairs.Label != "Combiner 'pairs'" || pairs.Mode != "EACH" || pairs.Min != 3 || pairs.Max != 3 || pairs.Satisfied || pairs.Saturated {
+		t.Errorf("unexpected combiner report: %+v", pairs)
+	}
+
+	if counts := []int{pairs.Matchers[0].Count, pairs.Matchers[1].Count}; counts[0] != 2 || counts[1] != 1 {
+		t.Errorf("expected matcher counts [2 1], got %v", counts)
+	}
+
+	assertShortfall := func(summary string, actual []string, expected ...string) {
+		if strings.Join(actual, "; ") != strings.Join(expected, "; ") {
+			t.Errorf("expected %s shortfall %q, got %q", summary, expected, actual)
+		}
+	}
+
+	assertShortfall("each", pairs.Shortfall, "needs 1 more of matcher #0", "needs 2 more of matcher #1")
+	assertShortfall("keyed", report.Combiners[1].Shortfall, "needs 1 more for key w2")
+	assertShortfall("composite", report.Combiners[2].Shortfall, "needs any one of the combiners to be satisfied")
+	assertShortfall("ordered", report.Combiners[2].Combiners[0].Shortfall, "needs 1 more of matcher #1, in order")
+	assertShortfall("nested", report.Combiners[2].Combiners[1].Shortfall, "needs 1 more message(s) matching any matcher")
+	assertShortfall("optional", report.Combiners[3].Shortfall)
+	assertShortfall("layer", report.Shortfall, "needs combiner 'pairs' to be satisfied", "needs combiner #1 to be satisfied", "needs combiner #2 to be satisfied")
+
+	if keys := report.Combiners[1].Keys; len(keys) != 1 || keys[0].Key != "w1" || keys[0].Count != 1 {
+		t.Errorf("expected key counts [w1 => 1], got %+v", keys)
+	}
+
+	if optional := report.Combiners[3]; !optional.Optional || !optional.Satisfied {
+		t.Errorf("expected optional combiner to be reported as optional and satisfied: %+v", optional)
+	}
+}
+
+func Test_UnsatisfiedReport_AssertSatisfied(t *testing.T) {
+	ch := make(chan string, 10)
+	exp := chanassert.NewChannelExpecter(ch).ExpectQuorum(2,
+		chanassert.AtLeastNOf(2, chanassert.MatchEqual("a")),
+		chanassert.ExactlyNOfAny(2, chanassert.MatchEqual("b"), chanassert.MatchEqual("c")),
+		chanassert.BetweenNDistinct(2, 3, workerKey, chanassert.MatchAnything[string]()),
+	)
+
+	exp.Listen()
+	for _, m := range []string{"a", "b", "w1:a"} {
+		ch <- m
+	}
+
+	mock := &mockTestingT{}
+	exp.AssertSatisfied(mock, time.Millisecond*100)
+
+	expected := strings.Join([]string{
+		`  - Layer #0: AT LEAST 2 combiners must be satisfied ("QUORUM" mode)`,
+		`    * Combiner #0: at least 2 message(s) in total matching any of (NOT satisfied, NOT saturated)`,
+		`      + Matcher #0: equals "a" => 1 message(s)`,
+		`      + Shortfall: needs 1 more message(s) matching any matcher`,
+		`    * Combiner #1: exactly 2 message(s) matching any one of (NOT satisfied, NOT saturated)`,
+		`      + Matcher #0: equals "b" => 1 message(s)`,
+		`      + Matcher #1: equals "c" => 0 messages`,
+		`      + Shortfall: needs 1 more of any one matcher`,
+		`    * Combiner #2: between 2 and 3 distinct key(s), matching any of (NOT satisfied, NOT saturated)`,
+		`      + Key w1 => 1 message(s)`,
+		`      + Shortfall: needs 1 more distinct key(s)`,
+		`    * Shortfall: needs 2 more combiner(s) to be satisfied`,
+	}, "\n")
+
+	for _, seen := range mock.seen {
+		if strings.Contains(seen.message, chanassert.UnsatisfiedError{}.Error()) {
+			if !strings.Contains(seen.message, expected) {
+				t.Errorf("expected unsatisfied error to contain report:\n%s\nbut got:\n%s", expected, seen.message)
+			}
+
+			return
+		}
+	}
+
+	t.Errorf("expected AssertSatisfied to report an unsatisfied layer, but saw: %v", mock.seen)
+}
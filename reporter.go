@@ -0,0 +1,210 @@
+package chanassert
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// layerOutcome gathers the errors and messages of a layer within an [Export], so
+// that each layer can be reported as a test case by [WriteJUnit] and [WriteTAP].
+type layerOutcome[T any] struct {
+	label    string
+	errors   []ExportError
+	messages []MessageResult[T]
+	report   *LayerReport
+
+	// skipped is true if the layer was never reached by the expecter, and unsatisfied
+	// is true if the layer was reached, but never became satisfied. A layer is only
+	// reported as unsatisfied by an error once the expecter has been awaited.
+	skipped     bool
+	unsatisfied bool
+}
+
+func layerOutcomes[T any](export Export[T]) []layerOutcome[T] {
+	outcomes := make([]layerOutcome[T], 0, len(export.Layers))
+	for _, layer := range export.Layers {
+		outcomes = append(outcomes, layerOutcome[T]{
+			label:       nameLabel("Layer", layer.Index, layer.Name),
+			report:      layer.Report,
+			skipped:     layer.StartedAt == nil,
+			unsatisfied: layer.StartedAt != nil && layer.FinishedAt == nil && !layer.Satisfied,
+		})
+	}
+
+	for _, e := range export.Errors {
+		if e.LayerIdx != nil && *e.LayerIdx < len(outcomes) {
+			outcomes[*e.LayerIdx].errors = append(outcomes[*e.LayerIdx].errors, e)
+		}
+	}
+
+	for _, m := range export.Messages {
+		if m.LayerIdx >= 0 && m.LayerIdx < len(outcomes) {
+			outcomes[m.LayerIdx].messages = append(outcomes[m.LayerIdx].messages, m)
+		}
+	}
+
+	return outcomes
+}
+
+// unsatisfiedMessage is the failure reported for a layer which was reached, but
+// never became satisfied, when no error reports this (see [layerOutcome]).
+const unsatisfiedMessage = "layer was reached, but never became satisfied"
+
+// failureDetails describes the errors of the layer provided, including the near miss for
+// each rejection, and the report of the layer if it was not satisfied.
+func failureDetails[T any](outcome layerOutcome[T]) string {
+	builder := &strings.Builder{}
+	for _, e := range outcome.errors {
+		builder.WriteString(e.Error)
+		builder.WriteString("\n")
+
+		if e.NearMiss != nil {
+			fmt.Fprintf(builder, "Near miss: %s\n", e.NearMiss)
+		}
+	}
+
+	if outcome.unsatisfied && outcome.report != nil {
+		outcome.report.trace().PrintTrace(builder, false, 0)
+	}
+
+	return builder.String()
+}
+
+// messagesTrace returns the full trace of each of the messages provided.
+func messagesTrace[T any](messages []MessageResult[T]) string {
+	builder := &strings.Builder{}
+	for _, m := range messages {
+		m.PrettyPrint(builder, true)
+	}
+
+	return builder.String()
+}
+
+// otherErrors returns the errors of the export which do not
+// relate to a specific layer (e.g. a [TerminatedError]).
+func otherErrors[T any](export Export[T]) []string {
+	errs := make([]string, 0)
+	for _, e := range export.Errors {
+		if e.LayerIdx == nil {
+			errs = append(errs, e.Error)
+		}
+	}
+
+	return errs
+}
+
+type junitSuite struct {
+	XMLName   xml.Name    `xml:"testsuite"`
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Skipped   int         `xml:"skipped,attr"`
+	Cases     []junitCase `xml:"testcase"`
+	SystemOut string      `xml:"system-out,omitempty"`
+	SystemErr string      `xml:"system-err,omitempty"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure"`
+	Skipped   *junitSkipped `xml:"skipped"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Details string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit writes the [Export] provided to the writer as a JUnit XML <testsuite> element, which
+// may be embedded within a <testsuites> document. Each layer is reported as a test case (named after
+// the layer, see [Expecter.Named]), which fails if the layer rejected any messages or was not
+// satisfied. The trace of the messages processed by each layer is included as its system-out, and
+// layers which were never reached are reported as skipped.
+func WriteJUnit[T any](w io.Writer, suiteName string, export Export[T]) error {
+	suite := junitSuite{Name: suiteName, Tests: len(export.Layers)}
+	for _, outcome := range layerOutcomes(export) {
+		testCase := junitCase{Name: outcome.label, Classname: suiteName, SystemOut: messagesTrace(outcome.messages)}
+		switch {
+		case len(outcome.errors) > 0:
+			testCase.Failure = &junitFailure{
+				Message: outcome.errors[0].Error,
+				Type:    outcome.errors[0].Kind,
+				Details: failureDetails(outcome),
+			}
+			suite.Failures++
+		case outcome.unsatisfied:
+			testCase.Failure = &junitFailure{Message: unsatisfiedMessage, Type: "unsatisfied", Details: failureDetails(outcome)}
+			suite.Failures++
+		case outcome.skipped:
+			testCase.Skipped = &junitSkipped{Message: "layer was never reached"}
+			suite.Skipped++
+		}
+
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	suite.SystemErr = strings.Join(otherErrors(export), "\n")
+	for _, m := range export.Messages {
+		if m.Status == Ignored {
+			suite.SystemOut += messagesTrace([]MessageResult[T]{m})
+		}
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suite); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteTAP writes the [Export] provided to the writer as a TAP fragment: a plan, followed by a test
+// point for each layer (named after the layer, see [Expecter.Named]). A layer fails if it rejected any
+// messages or was not satisfied, in which case the errors and the trace of the messages processed
+// by the layer follow as diagnostics. Layers which were never reached are reported as skipped.
+func WriteTAP[T any](w io.Writer, export Export[T]) error {
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "1..%d\n", len(export.Layers))
+	for idx, outcome := range layerOutcomes(export) {
+		switch {
+		case len(outcome.errors) > 0:
+			fmt.Fprintf(builder, "not ok %d - %s\n", idx+1, outcome.label)
+			writeTAPDiagnostics(builder, failureDetails(outcome)+messagesTrace(outcome.messages))
+		case outcome.unsatisfied:
+			fmt.Fprintf(builder, "not ok %d - %s\n", idx+1, outcome.label)
+			writeTAPDiagnostics(builder, unsatisfiedMessage+"\n"+failureDetails(outcome)+messagesTrace(outcome.messages))
+		case outcome.skipped:
+			fmt.Fprintf(builder, "ok %d - %s # SKIP layer was never reached\n", idx+1, outcome.label)
+		default:
+			fmt.Fprintf(builder, "ok %d - %s\n", idx+1, outcome.label)
+		}
+	}
+
+	writeTAPDiagnostics(builder, strings.Join(otherErrors(export), "\n"))
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+func writeTAPDiagnostics(builder *strings.Builder, diagnostics string) {
+	for _, line := range strings.Split(strings.TrimRight(diagnostics, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+
+		builder.WriteString("# ")
+		builder.WriteString(line)
+		builder.WriteString("\n")
+	}
+}
@@ -0,0 +1,154 @@
+package chanassert_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hbomb79/go-chanassert"
+)
+
+// makeReportedExpecter returns an expecter whose first layer is satisfied (after
+// rejecting a message), whose second layer is unsatisfied, and whose third layer is never reached.
+func makeReportedExpecter() chanassert.Expecter[string] {
+	ch := make(chan string, 10)
+	exp := chanassert.NewChannelExpecter(ch).
+		Expect(chanassert.OneOf(chanassert.MatchEqual("hello"))).Named("greeting").
+		Expect(chanassert.ExactlyNOf(2, chanassert.MatchEqual("world"))).
+		Expect(chanassert.OneOf(chanassert.MatchEqual("bye")))
+
+	exp.Listen()
+	for _, m := range []string{"world", "hello", "world"} {
+		ch <- m
+	}
+	exp.AwaitSatisfied(time.Millisecond * 100)
+
+	return exp
+}
+
+func Test_WriteJUnit(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	if err := chanassert.WriteJUnit(buffer, "conformance", makeReportedExpecter().Export()); err != nil {
+		t.Fatalf("unexpected error writing JUnit XML: %s", err)
+	}
+
+	var suite struct {
+		Name     string `xml:"name,attr"`
+		Tests    int    `xml:"tests,attr"`
+		Failures int    `xml:"failures,attr"`
+		Skipped  int    `xml:"skipped,attr"`
+		Cases    []struct {
+			Name    string `xml:"name,attr"`
+			Failure *struct {
+				Message string `xml:"message,attr"`
+				Type    string `xml:"type,attr"`
+				Details string `xml:",chardata"`
+			} `xml:"failure"`
+			Skipped   *struct{} `xml:"skipped"`
+			SystemOut string    `xml:"system-out"`
+		} `xml:"testcase"`
+		SystemErr string `xml:"system-err"`
+	}
+	if err := xml.Unmarshal(buffer.Bytes(), &suite); err != nil {
+		t.Fatalf("unexpected error decoding JUnit XML: %s\n%s", err, buffer)
+	}
+
+	if suite.Name != "conformance" || suite.Tests != 3 || suite.Failures != 2 || suite.Skipped != 1 || len(suite.Cases) != 3 {
+		t.Fatalf("unexpected test suite:\n%s", buffer)
+	}
+
+	greeting, second, third := suite.Cases[0], suite.Cases[1], suite.Cases[2]
+	if greeting.Name != "Layer 'greeting'" || greeting.Failure == nil || greeting.Failure.Type != "rejection" {
+		t.Errorf("expected layer 'greeting' to fail with a rejection:\n%s", buffer)
+	} else if !strings.Contains(greeting.Failure.Details, "Near miss: would have matched layer #1") {
+		t.Errorf("expected rejection to include near miss, got:\n%s", greeting.Failure.Details)
+	}
+
+	if !strings.Contains(greeting.SystemOut, "Message 'hello' - ACCEPTED") || !strings.Contains(greeting.SystemOut, "[DEBUG] Layer Status") {
+		t.Errorf("expected system-out of layer 'greeting' to contain the full trace, got:\n%s", greeting.SystemOut)
+	}
+
+	if second.Name != "Layer #1" || second.Failure == nil || second.Failure.Type != "unsatisfied" {
+		t.Errorf("expected layer #1 to fail as unsatisfied:\n%s", buffer)
+	} else if !strings.Contains(second.Failure.Details, "Shortfall: needs 1 more message(s) matching any matcher") {
+		t.Errorf("expected unsatisfied failure to include layer report, got:\n%s", second.Failure.Details)
+	}
+
+	if third.Skipped == nil || third.Failure != nil {
+		t.Errorf("expected layer #2 to be skipped:\n%s", buffer)
+	}
+
+	if !strings.Contains(suite.SystemErr, chanassert.TerminatedError{Timeout: time.Millisecond * 100}.Error()) {
+		t.Errorf("expected system-err to contain termination error, got %q", suite.SystemErr)
+	}
+}
+
+func Test_WriteTAP(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	if err := chanassert.WriteTAP(buffer, makeReportedExpecter().Export()); err != nil {
+		t.Fatalf("unexpected error writing TAP: %s", err)
+	}
+
+	lines := strings.Split(buffer.String(), "\n")
+	testPoints := make([]string, 0)
+	for _, line := range lines {
+		if line != "" && !strings.HasPrefix(line, "# ") {
+			testPoints = append(testPoints, line)
+		}
+	}
+
+	expected := []string{
+		"1..3",
+		"not ok 1 - Layer 'greeting'",
+		"not ok 2 - Layer #1",
+		"ok 3 - Layer #2 # SKIP layer was never reached",
+	}
+	if strings.Join(testPoints, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected test points:\n%s\nbut got:\n%s", strings.Join(expected, "\n"), buffer)
+	}
+
+	for _, diagnostic := range []string{
+		"# message #0 (world) was unexpected by layer 'greeting'",
+		"# Near miss: would have matched layer #1, combiner #0, matcher #0 (equals \"world\"): did it arrive too early?",
+		"# active layer (layer #1) never became satisfied",
+		"#       + Shortfall: needs 1 more message(s) matching any matcher",
+		"# Message 'world' - ACCEPTED:",
+		"# expecter did not finish within the 100ms timeout specified",
+	} {
+		if !strings.Contains(buffer.String(), diagnostic+"\n") {
+			t.Errorf("expected TAP to contain diagnostic %q, but it did not:\n%s", diagnostic, buffer)
+		}
+	}
+}
+
+func Test_WriteTAP_WithoutErrors(t *testing.T) {
+	// An export taken before the expecter was awaited has no errors,
+	// and so the outcome of each layer is inferred from its timing
+	started, finished := time.Now(), time.Now()
+	export := chanassert.Export[string]{
+		Version: chanassert.ExportVersion,
+		Layers: []chanassert.ExportLayer{
+			{Index: 0, Satisfied: true, StartedAt: &started, FinishedAt: &finished},
+			{Index: 1, StartedAt: &started},
+			{Index: 2},
+		},
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := chanassert.WriteTAP(buffer, export); err != nil {
+		t.Fatalf("unexpected error writing TAP: %s", err)
+	}
+
+	expected := strings.Join([]string{
+		"1..3",
+		"ok 1 - Layer #0",
+		"not ok 2 - Layer #1",
+		"# layer was reached, but never became satisfied",
+		"ok 3 - Layer #2 # SKIP layer was never reached",
+	}, "\n") + "\n"
+	if buffer.String() != expected {
+		t.Errorf("expected TAP:\n%s\nbut got:\n%s", expected, buffer)
+	}
+}
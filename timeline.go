@@ -0,0 +1,175 @@
+package chanassert
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// timedLayer is implemented by layers which have a timeout (see [Expecter.ExpectTimeout]).
+type timedLayer interface {
+	layerTimeout() *time.Duration
+}
+
+// layerSpan records when a layer was selected by the expecter, and when it became satisfied.
+type layerSpan struct {
+	started  *time.Time
+	finished *time.Time
+}
+
+func (span *layerSpan) begin() {
+	if span.started == nil {
+		now := time.Now()
+		span.started = &now
+	}
+}
+
+func (span *layerSpan) finish() {
+	now := time.Now()
+	span.finished = &now
+}
+
+// The threads of the Chrome trace, which are shown as separate tracks.
+const (
+	chromeLayersThread   = 1
+	chromeMessagesThread = 2
+)
+
+// chromeStatusColors are the reserved Chrome trace colour names used for each message status.
+var chromeStatusColors = map[MessageStatus]string{
+	Accepted: "good",
+	Rejected: "terrible",
+	Ignored:  "grey",
+}
+
+type chromeTrace struct {
+	TraceEvents     []chromeEvent `json:"traceEvents"`
+	DisplayTimeUnit string        `json:"displayTimeUnit"`
+}
+
+type chromeEvent struct {
+	Name      string         `json:"name"`
+	Category  string         `json:"cat,omitempty"`
+	Phase     string         `json:"ph"`
+	Timestamp float64        `json:"ts"`
+	Duration  *float64       `json:"dur,omitempty"`
+	PID       int            `json:"pid"`
+	TID       int            `json:"tid"`
+	Scope     string         `json:"s,omitempty"`
+	Color     string         `json:"cname,omitempty"`
+	Args      map[string]any `json:"args,omitempty"`
+}
+
+// WriteChromeTrace writes the [Export] provided to the writer in the Chrome Trace Event format, which can
+// be viewed as a timeline in Perfetto (https://ui.perfetto.dev) or chrome://tracing. The period each layer
+// was active for is shown as a span on the "Layers" track, and each message as an instant event on the
+// "Messages" track, coloured by its status. Layer timeouts which elapsed, and the termination of the
+// expecter (see [TerminatedError]), are shown as markers spanning every track.
+//
+// Timestamps are relative to when the expecter began listening. A layer which never became satisfied is
+// shown as active until the expecter finished, so the export should only be taken once the expecter has finished.
+func WriteChromeTrace[T any](w io.Writer, export Export[T]) error {
+	origin := export.StartedAt
+	end := export.FinishedAt
+	for _, m := range export.Messages {
+		if m.ReceivedAt.After(end) {
+			end = m.ReceivedAt
+		}
+	}
+
+	micros := func(t time.Time) float64 {
+		return float64(t.Sub(origin).Nanoseconds()) / float64(time.Microsecond)
+	}
+
+	trace := chromeTrace{
+		DisplayTimeUnit: "ms",
+		TraceEvents: []chromeEvent{
+			{Name: "process_name", Phase: "M", PID: 1, Args: map[string]any{"name": "chanassert"}},
+			{Name: "thread_name", Phase: "M", PID: 1, TID: chromeLayersThread, Args: map[string]any{"name": "Layers"}},
+			{Name: "thread_name", Phase: "M", PID: 1, TID: chromeMessagesThread, Args: map[string]any{"name": "Messages"}},
+		},
+	}
+
+	for _, layer := range export.Layers {
+		if layer.StartedAt == nil {
+			continue
+		}
+
+		finished := end
+		if layer.FinishedAt != nil {
+			finished = *layer.FinishedAt
+		}
+
+		duration := micros(finished) - micros(*layer.StartedAt)
+		trace.TraceEvents = append(trace.TraceEvents, chromeEvent{
+			Name:      nameLabel("Layer", layer.Index, layer.Name),
+			Category:  "layer",
+			Phase:     "X",
+			Timestamp: micros(*layer.StartedAt),
+			Duration:  &duration,
+			PID:       1,
+			TID:       chromeLayersThread,
+			Args:      map[string]any{"description": layer.Description, "satisfied": layer.Satisfied},
+		})
+
+		if layer.Timeout == nil {
+			continue
+		}
+
+		if deadline := layer.StartedAt.Add(*layer.Timeout); !deadline.After(finished) {
+			trace.TraceEvents = append(trace.TraceEvents, chromeEvent{
+				Name:      fmt.Sprintf("%s timeout (%s)", nameLabel("Layer", layer.Index, layer.Name), layer.Timeout),
+				Category:  "timeout",
+				Phase:     "i",
+				Timestamp: micros(deadline),
+				PID:       1,
+				TID:       chromeLayersThread,
+				Scope:     "g",
+			})
+		}
+	}
+
+	for idx, m := range export.Messages {
+		args := map[string]any{"message": m.formatted(), "status": m.Status.String(), "trace": m.Trace.Message}
+		if m.LayerIdx >= 0 {
+			args["layer"] = nameLabel("Layer", m.LayerIdx, m.LayerName)
+		}
+
+		if len(m.Captures) > 0 {
+			args["captures"] = m.captures().String()
+		}
+
+		trace.TraceEvents = append(trace.TraceEvents, chromeEvent{
+			Name:      fmt.Sprintf("Message #%d - %s", idx, m.Status),
+			Category:  "message",
+			Phase:     "i",
+			Timestamp: micros(m.ReceivedAt),
+			PID:       1,
+			TID:       chromeMessagesThread,
+			Scope:     "t",
+			Color:     chromeStatusColors[m.Status],
+			Args:      args,
+		})
+	}
+
+	for _, e := range export.Errors {
+		if e.Kind == "terminated" {
+			trace.TraceEvents = append(trace.TraceEvents, chromeEvent{
+				Name:      "Expecter terminated",
+				Category:  "terminated",
+				Phase:     "i",
+				Timestamp: micros(export.FinishedAt),
+				PID:       1,
+				TID:       chromeLayersThread,
+				Scope:     "g",
+				Args:      map[string]any{"error": e.Error},
+			})
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(trace)
+}
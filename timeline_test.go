@@ -0,0 +1,77 @@
+package chanassert_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hbomb79/go-chanassert"
+)
+
+func Test_WriteChromeTrace(t *testing.T) {
+	ch := make(chan string, 2)
+	exp := chanassert.NewChannelExpecter(ch).
+		Expect(chanassert.OneOf(chanassert.MatchEqual("a"))).Named("first").
+		ExpectTimeout(time.Millisecond*10, chanassert.OneOf(chanassert.MatchEqual("b")))
+
+	exp.Listen()
+	ch <- "a"
+	time.Sleep(time.Millisecond * 30)
+	ch <- "b"
+	exp.AwaitSatisfied(time.Millisecond * 50)
+
+	buffer := &bytes.Buffer{}
+	if err := chanassert.WriteChromeTrace(buffer, exp.Export()); err != nil {
+		t.Fatalf("unexpected error writing Chrome trace: %s", err)
+	}
+
+	var trace struct {
+		TraceEvents []struct {
+			Name     string         `json:"name"`
+			Category string         `json:"cat"`
+			Phase    string         `json:"ph"`
+			TS       float64        `json:"ts"`
+			Dur      *float64       `json:"dur"`
+			TID      int            `json:"tid"`
+			Scope    string         `json:"s"`
+			Color    string         `json:"cname"`
+			Args     map[string]any `json:"args"`
+		} `json:"traceEvents"`
+	}
+	if err := json.Unmarshal(buffer.Bytes(), &trace); err != nil {
+		t.Fatalf("unexpected error decoding Chrome trace: %s\n%s", err, buffer)
+	}
+
+	events := make(map[string]int)
+	for idx, event := range trace.TraceEvents {
+		events[event.Name] = idx
+	}
+
+	first, second := trace.TraceEvents[events["Layer 'first'"]], trace.TraceEvents[events["Layer #1"]]
+	if first.Phase != "X" || first.Dur == nil || second.Phase != "X" || second.Dur == nil {
+		t.Fatalf("expected a span for each layer:\n%s", buffer)
+	}
+
+	if second.TS < first.TS+*first.Dur || *second.Dur < float64((time.Millisecond*50).Microseconds()) {
+		t.Errorf("expected layer #1 to be active from the end of layer 'first' until termination:\n%s", buffer)
+	}
+
+	for name, color := range map[string]string{"Message #0 - ACCEPTED": "good", "Message #1 - REJECTED": "terrible"} {
+		idx, ok := events[name]
+		if !ok {
+			t.Errorf("expected an instant event named %q:\n%s", name, buffer)
+		} else if event := trace.TraceEvents[idx]; event.Phase != "i" || event.Color != color || event.Category != "message" {
+			t.Errorf("expected message event %q to be an instant coloured %q:\n%s", name, color, buffer)
+		}
+	}
+
+	for _, name := range []string{"Layer #1 timeout (10ms)", "Expecter terminated"} {
+		idx, ok := events[name]
+		if !ok {
+			t.Errorf("expected a marker named %q:\n%s", name, buffer)
+		} else if event := trace.TraceEvents[idx]; event.Phase != "i" || event.Scope != "g" {
+			t.Errorf("expected %q to be a global instant event:\n%s", name, buffer)
+		}
+	}
+}
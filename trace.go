@@ -3,52 +3,181 @@ package chanassert
 import (
 	"fmt"
 	"io"
+	"reflect"
+	"regexp"
 	"strings"
+	"time"
 )
 
-type messageMode int
+// TraceLevel is the verbosity of a trace message. When a trace is printed, only
+// the messages at or below the verbosity of the expecter (see [Expecter.Verbosity]) are included.
+//
+// LevelInfo is the zero value, so a [TraceMessage] which does not specify its Mode is an info message.
+type TraceLevel int
 
 const (
-	info messageMode = iota
-	debug
+	// LevelError messages explain why a message was rejected.
+	LevelError TraceLevel = iota - 1
+	// LevelInfo messages describe the path each message took through
+	// the expecter. This is the default verbosity of an expecter.
+	LevelInfo
+	// LevelDebug messages explain the state of each layer and combiner
+	// (e.g. why a combiner is, or is not, satisfied).
+	LevelDebug
+	// LevelTrace messages contain the finest details, such as the number of
+	// messages matched by each matcher. This is the verbosity of [Expecter.Debug].
+	LevelTrace
 )
 
+var traceLevels = []TraceLevel{LevelError, LevelInfo, LevelDebug, LevelTrace}
+
+func (level TraceLevel) String() string {
+	//exhaustive:enforce
+	switch level {
+	case LevelError:
+		return "error"
+	case LevelInfo:
+		return "info"
+	case LevelDebug:
+		return "debug"
+	case LevelTrace:
+		return "trace"
+	}
+
+	return fmt.Sprintf("TraceLevel(%d)", int(level))
+}
+
+func (level TraceLevel) MarshalText() ([]byte, error) {
+	if level < LevelError || level > LevelTrace {
+		return nil, fmt.Errorf("unknown trace level %d", level)
+	}
+
+	return []byte(level.String()), nil
+}
+
+func (level *TraceLevel) UnmarshalText(text []byte) error {
+	for _, l := range traceLevels {
+		if l.String() == string(text) {
+			*level = l
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown trace level %q", text)
+}
+
+// debugLevel returns the verbosity of a trace printed with or without debug output.
+func debugLevel(includeDebug bool) TraceLevel {
+	if includeDebug {
+		return LevelTrace
+	}
+
+	return LevelInfo
+}
+
 type TraceMessage struct {
-	Message string
-	Nested  []TraceMessage
-	Mode    messageMode
+	Message string         `json:"message"`
+	Nested  []TraceMessage `json:"nested,omitempty"`
+	Mode    TraceLevel     `json:"mode"`
+}
+
+// NewTrace returns a trace message which is only included when the
+// trace is printed with a verbosity of at least the level provided.
+func NewTrace(level TraceLevel, message string, nested ...TraceMessage) TraceMessage {
+	return TraceMessage{Message: message, Nested: nested, Mode: level}
+}
+
+// NewErrorTrace returns a trace message which explains why a message was
+// rejected. It is included in the trace regardless of the verbosity.
+func NewErrorTrace(message string, nested ...TraceMessage) TraceMessage {
+	return newErrorTrace(message, nested...)
+}
+
+// NewInfoTrace returns a trace message which is always included
+// when the trace is printed.
+func NewInfoTrace(message string, nested ...TraceMessage) TraceMessage {
+	return newInfoTrace(message, nested...)
+}
+
+// NewDebugTrace returns a trace message which is only included
+// when the trace is printed with debug output enabled.
+func NewDebugTrace(message string, nested ...TraceMessage) TraceMessage {
+	return newDebugTrace(message, nested...)
+}
+
+func newErrorTrace(message string, nested ...TraceMessage) TraceMessage {
+	return NewTrace(LevelError, message, nested...)
 }
 
 func newInfoTrace(message string, nested ...TraceMessage) TraceMessage {
-	return TraceMessage{Message: message, Nested: nested, Mode: info}
+	return NewTrace(LevelInfo, message, nested...)
 }
 
 func newDebugTrace(message string, nested ...TraceMessage) TraceMessage {
-	return TraceMessage{Message: message, Nested: nested, Mode: debug}
+	return NewTrace(LevelDebug, message, nested...)
+}
+
+// IsDebug returns true if the trace message is only included
+// when the trace is printed with debug output enabled.
+func (msg TraceMessage) IsDebug() bool {
+	return msg.Mode > LevelInfo
+}
+
+// atLevel returns a copy of the trace message with the messages nested within it which
+// are more verbose than the level provided (and the messages nested within those) removed.
+func (msg TraceMessage) atLevel(level TraceLevel) TraceMessage {
+	nested := make([]TraceMessage, 0, len(msg.Nested))
+	for _, trace := range msg.Nested {
+		if trace.Mode <= level {
+			nested = append(nested, trace.atLevel(level))
+		}
+	}
+
+	return TraceMessage{Message: msg.Message, Nested: nested, Mode: msg.Mode}
+}
+
+// traceLine returns the text of the trace message, marking debug messages (at either
+// [LevelDebug] or [LevelTrace]).
+func traceLine(msg TraceMessage) string {
+	if msg.IsDebug() {
+		return "[DEBUG] " + msg.Message
+	}
+
+	return msg.Message
 }
 
 var levelPrefixes = []rune{'-', '*', '+', '>'}
 
+// PrintTrace prints the trace message, and the messages nested within it, as an indented list. Debug
+// messages (at either [LevelDebug] or [LevelTrace]), and the messages nested within them, are only
+// printed if includeDebug is true.
 func (msg TraceMessage) PrintTrace(writer io.Writer, includeDebug bool, nestLevel int) {
-	fmt.Fprint(writer, strings.Repeat("  ", nestLevel+1))
+	if msg.IsDebug() && !includeDebug {
+		return
+	}
 
 	prefix := levelPrefixes[nestLevel%len(levelPrefixes)]
-
-	//exhaustive:enforce
-	switch msg.Mode {
-	case info:
-		fmt.Fprintf(writer, "%c %s\n", prefix, msg.Message)
-	case debug:
-		if includeDebug {
-			fmt.Fprintf(writer, "%c [DEBUG] %s\n", prefix, msg.Message)
-		}
-	}
+	fmt.Fprintf(writer, "%s%c %s\n", strings.Repeat("  ", nestLevel+1), prefix, traceLine(msg))
 
 	for _, trace := range msg.Nested {
 		trace.PrintTrace(writer, includeDebug, nestLevel+1)
 	}
 }
 
+var (
+	addressPattern  = regexp.MustCompile(`\b0x[0-9a-f]{6,}\b`)
+	durationPattern = regexp.MustCompile(`\b(?:[0-9]+(?:\.[0-9]+)?(?:ns|us|µs|ms|s|m|h))+\b`)
+)
+
+// NormalizeTrace replaces the content of a printed trace which can vary from run to run with
+// placeholders, so that the trace can be compared byte-for-byte (e.g. against a golden file).
+// Memory addresses (e.g. of pointer messages) are replaced with "0x?", and durations (e.g. layer
+// timeouts, which are commonly scaled on slower machines) are replaced with "<duration>".
+func NormalizeTrace(trace string) string {
+	trace = addressPattern.ReplaceAllString(trace, "0x?")
+	return durationPattern.ReplaceAllString(trace, "<duration>")
+}
+
 type MessageStatus int
 
 const (
@@ -71,16 +200,96 @@ func (m MessageStatus) String() string {
 	panic("unreachable")
 }
 
+func (m MessageStatus) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *MessageStatus) UnmarshalText(text []byte) error {
+	for _, status := range []MessageStatus{Accepted, Ignored, Rejected} {
+		if status.String() == string(text) {
+			*m = status
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown message status %q", text)
+}
+
 type MessageResult[T any] struct {
-	Message  T
-	LayerIdx int
-	Status   MessageStatus
-	Trace    TraceMessage
+	Message T `json:"message"`
+
+	// Formatted is the message as formatted for the trace and errors of
+	// the expecter which processed it (see [Expecter.FormatMessages]).
+	Formatted string `json:"formatted,omitempty"`
+
+	LayerIdx int `json:"layer_idx"`
+
+	// LayerName is the name of the layer which processed the message
+	// (see [Expecter.Named]), or an empty string if it was not named.
+	LayerName string `json:"layer_name,omitempty"`
+
+	Status MessageStatus `json:"status"`
+	Trace  TraceMessage  `json:"trace"`
+
+	// Captures contains the values bound (see [Capture]) as
+	// a result of this message being accepted, if any.
+	Captures Captures `json:"captures,omitempty"`
+	// ReceivedAt is when the message was received by the expecter.
+	ReceivedAt time.Time `json:"received_at"`
+
+	// isFormatted is true if Formatted was set by an expecter, as
+	// a formatter is free to format a message as an empty string.
+	isFormatted bool
+	// formattedCaptures are the Captures as formatted by the formatter of the expecter, with
+	// any redacted values redacted. They are used in place of Captures when printing the result.
+	formattedCaptures Captures
+	// omitMessage is true if Message is omitted from the JSON encoding (see [WithoutRawMessages]).
+	omitMessage bool
+	// nearMiss is the near miss of a rejected message, which is found when the message is rejected
+	// so that matchers which refer to captured values see the values bound at that point.
+	nearMiss *NearMiss
 }
 
+// PrettyPrint prints the trace of the message using the [IndentRenderer].
 func (result MessageResult[T]) PrettyPrint(writer io.Writer, includeDebug bool) {
-	fmt.Fprintf(writer, "Message '%+v' - %s:\n", result.Message, result.Status)
+	IndentRenderer().RenderTrace(writer, result.entry(0, debugLevel(includeDebug)))
+}
+
+// formatted returns the formatted message, formatting it with the default
+// formatter if the result was not produced by an expecter.
+func (result MessageResult[T]) formatted() string {
+	if result.isFormatted {
+		return result.Formatted
+	}
 
-	result.Trace.PrintTrace(writer, includeDebug, 0)
-	fmt.Fprintln(writer, "")
+	return MessageFormatter[T]().Format(result.Message)
+}
+
+// captures returns the captures to print for this result, formatting them with
+// the default formatter if the result was not produced by an expecter.
+func (result MessageResult[T]) captures() Captures {
+	if result.formattedCaptures != nil || len(result.Captures) == 0 {
+		return result.formattedCaptures
+	}
+
+	formatted := make(Captures, len(result.Captures))
+	for name, value := range result.Captures {
+		formatted[name] = defaultValueFormatter.formatCaptured(reflect.ValueOf(result.Message), value)
+	}
+
+	return formatted
+}
+
+// entry returns the trace entry for this result, which was the message at the position
+// provided. Trace messages more verbose than the level provided are removed from the trace.
+func (result MessageResult[T]) entry(messageNum int, level TraceLevel) TraceEntry {
+	return TraceEntry{
+		MessageNum: messageNum,
+		Message:    result.formatted(),
+		LayerIdx:   result.LayerIdx,
+		LayerName:  result.LayerName,
+		Status:     result.Status,
+		Trace:      result.Trace.atLevel(level),
+		Captures:   result.captures(),
+	}
 }
@@ -1,11 +1,6 @@
 package chanassert_test
 
 import (
-	"embed"
-	"errors"
-	"io/fs"
-	"os"
-	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -13,54 +8,6 @@ import (
 	"github.com/hbomb79/go-chanassert"
 )
 
-const traceDirPath = "testdata/traces/"
-
-//go:embed testdata/traces/**
-var expectations embed.FS
-
-func getTestExpectation(t *testing.T) (string, error) {
-	name := t.Name()
-	contents, err := expectations.ReadFile(traceDirPath + name)
-	if err != nil {
-		return "", err
-	}
-
-	return strings.TrimSpace(string(contents)), nil
-}
-
-func assertTraceExpected(t *testing.T, expecter chanassert.Expecter[string]) {
-	builder := &strings.Builder{}
-	expecter.FPrintTrace(builder)
-	actual := builder.String()
-
-	expected, err := getTestExpectation(t)
-	if err != nil {
-		if errors.Is(err, fs.ErrNotExist) {
-			name := filepath.Base(t.Name())
-			dir := filepath.Dir(t.Name())
-			t.Logf("WARN: trace expectation for this test MISSING... Creating (dir=%q name=%q)", dir, name)
-
-			if err := os.MkdirAll(filepath.Join(traceDirPath, dir), os.ModePerm); err != nil {
-				t.Fatalf("Failed to automatically create trace expectation testdata dir: %s", err)
-			}
-
-			if err := os.WriteFile(filepath.Join(traceDirPath, dir, name), []byte(actual), os.ModePerm); err != nil {
-				t.Fatalf("Failed to automatically create trace expectation testdata file: %s", err)
-			}
-
-			t.Skipf("SKIPPED: test expectation did not exist, rerun test")
-		} else {
-			t.Fatalf("failed to open embedded file '%s' for test expectation: %v\nExpecter trace follows:\n%s\n", t.Name(), err, actual)
-		}
-	}
-
-	actualTrimmed := strings.TrimSpace(actual)
-	expectedTrimmed := strings.TrimSpace(expected)
-	if expectedTrimmed != actualTrimmed {
-		t.Fatalf("Expected message did not match actual:\n-- Expected --\n%s\n\n-- Actual --\n%s\n", expectedTrimmed, actualTrimmed)
-	}
-}
-
 func runTraceTests(t *testing.T, makeExpecter func() (chan string, chanassert.Expecter[string]), tests []traceTest) {
 	for _, data := range tests {
 		t.Run(data.summary, func(t *testing.T) {
@@ -95,7 +42,7 @@ func runTraceTests(t *testing.T, makeExpecter func() (chan string, chanassert.Ex
 				}
 			}
 
-			assertTraceExpected(t, expecter)
+			chanassert.AssertTraceGolden(t, expecter)
 		})
 	}
 }
@@ -424,3 +371,121 @@ func Test_Trace_MultipleLayer_MultipleCombiner(t *testing.T) {
 
 	runTraceTests(t, makeExpecter, tests)
 }
+
+func Test_Trace_QuorumAndExclusiveLayers(t *testing.T) {
+	makeExpecter := func() (chan string, chanassert.Expecter[string]) {
+		c := make(chan string, 10)
+		return c, chanassert.
+			NewChannelExpecter(c).
+			ExpectQuorum(2,
+				chanassert.OneOf(chanassert.MatchEqual("a")),
+				chanassert.OneOf(chanassert.MatchEqual("b")),
+				chanassert.OneOf(chanassert.MatchEqual("c")),
+			).
+			ExpectExclusive(
+				chanassert.AllOf(chanassert.MatchEqual("x1"), chanassert.MatchEqual("x2")),
+				chanassert.AllOf(chanassert.MatchEqual("y1"), chanassert.MatchEqual("y2")),
+			)
+	}
+
+	tests := []traceTest{
+		{
+			summary: "Messages delivered in order",
+			messages: []message{
+				// Layer 0
+				{"a", chanassert.Accepted},
+				{"c", chanassert.Accepted},
+				// Layer 1
+				{"y1", chanassert.Accepted},
+				{"y2", chanassert.Accepted},
+			},
+			shouldBeSatisfied: true,
+		},
+		{
+			summary: "Exclusive path switched",
+			messages: []message{
+				// Layer 0
+				{"b", chanassert.Accepted},
+				{"a", chanassert.Accepted},
+				// Layer 1
+				{"x1", chanassert.Accepted},
+				{"y1", chanassert.Rejected},
+			},
+			shouldBeSatisfied: false,
+		},
+	}
+
+	runTraceTests(t, makeExpecter, tests)
+}
+
+func Test_Trace_Deterministic(t *testing.T) {
+	makeCombiners := func() []chanassert.Combiner[string] {
+		return []chanassert.Combiner[string]{
+			chanassert.BetweenNOfEach(2, 3, chanassert.MatchEqual("a"), chanassert.MatchEqual("b"), chanassert.MatchEqual("c"), chanassert.MatchEqual("d")),
+			chanassert.AtLeastNOfAny(1, chanassert.MatchEqual("a"), chanassert.MatchEqual("b"), chanassert.MatchEqual("c"), chanassert.MatchEqual("d")),
+		}
+	}
+
+	// The trace of each message must be identical across many runs, as
+	// Go randomises the order in which maps are iterated
+	traces := func() string {
+		builder := &strings.Builder{}
+		for _, combiner := range makeCombiners() {
+			for _, m := range []string{"d", "c", "b", "a"} {
+				_, trace := combiner.TryMatch(m)
+				trace.PrintTrace(builder, true, 0)
+			}
+		}
+
+		return builder.String()
+	}
+
+	expected := traces()
+	for run := 0; run < 50; run++ {
+		if actual := traces(); actual != expected {
+			t.Fatalf("trace differed between runs:\n-- First run --\n%s\n-- Run #%d --\n%s", expected, run, actual)
+		}
+	}
+
+	for _, line := range []string{
+		"ALL matchers needs to match at least 2 messages, but matchers [#0, #1, #2, #3] have not",
+		"Matcher #2 has matched against minimum messages (1)",
+		"Matcher #0 has matched against minimum messages (1)",
+	} {
+		if !strings.Contains(expected, line) {
+			t.Errorf("expected trace to contain %q, but it did not:\n%s", line, expected)
+		}
+	}
+}
+
+func Test_NormalizeTrace(t *testing.T) {
+	tests := []struct {
+		trace    string
+		expected string
+	}{
+		{"Message 0xc000012345 (*string) REJECTED", "Message 0x? (*string) REJECTED"},
+		{"timeout of layer (1.5s) has been reached", "timeout of layer (<duration>) has been reached"},
+		{"within 2m30s, or 100ms, or 250µs", "within <duration>, or <duration>, or <duration>"},
+		{"Matcher #0 => 12 message(s)", "Matcher #0 => 12 message(s)"},
+		{"Message 'v2s' - ACCEPTED", "Message 'v2s' - ACCEPTED"},
+	}
+
+	for _, test := range tests {
+		if actual := chanassert.NormalizeTrace(test.trace); actual != test.expected {
+			t.Errorf("expected %q to normalise to %q, got %q", test.trace, test.expected, actual)
+		}
+	}
+
+	ch := make(chan string, 1)
+	exp := chanassert.NewChannelExpecter(ch).ExpectTimeout(time.Millisecond*10, chanassert.OneOf(chanassert.MatchEqual("a"))).Normalize()
+	exp.Listen()
+	time.Sleep(time.Millisecond * 20)
+	ch <- "a"
+	exp.AwaitSatisfied(time.Millisecond * 100)
+
+	builder := &strings.Builder{}
+	exp.FPrintTrace(builder)
+	if expected := "timeout of layer (<duration>) has been reached"; !strings.Contains(builder.String(), expected) {
+		t.Errorf("expected normalised trace to contain %q, got:\n%s", expected, builder.String())
+	}
+}